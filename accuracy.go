@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/genuinetools/weather/config"
+	"github.com/genuinetools/weather/observations"
+)
+
+const accuracyHelp = `Show how accurate the recorded forecasts of a saved location were.
+
+The forecasts and the weather are recorded by "weather record", the forecasts
+of each server it is pointed at as a provider. For each provider and lead
+time this shows the mean absolute error of the temperature forecast and how
+often rain was forecast for the hours it rained and did not.`
+
+func (cmd *accuracyCommand) Name() string      { return "accuracy" }
+func (cmd *accuracyCommand) Args() string      { return "[OPTIONS] NAME" }
+func (cmd *accuracyCommand) ShortHelp() string { return "Show the accuracy of past forecasts." }
+func (cmd *accuracyCommand) LongHelp() string  { return accuracyHelp }
+func (cmd *accuracyCommand) Hidden() bool      { return false }
+
+func (cmd *accuracyCommand) Register(fs *flag.FlagSet) {
+	fs.IntVar(&cmd.last, "last", 30, "number of days of forecasts to score, up to today")
+	fs.StringVar(&cmd.dir, "dir", filepath.Join(config.StateDir(), "observations"), "directory the observations are kept in")
+}
+
+type accuracyCommand struct {
+	last int
+	dir  string
+}
+
+func (cmd *accuracyCommand) Run(ctx context.Context, args []string) error {
+	if cmd.last < 1 {
+		return fmt.Errorf("last must be at least 1 day")
+	}
+
+	store, err := observations.Open(cmd.dir)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		names, err := store.Locations()
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("pass the name of a location, none are recorded in %s", cmd.dir)
+		}
+		return fmt.Errorf("pass the name of a location, one of: %s", strings.Join(names, ", "))
+	}
+	name := args[0]
+
+	obs, err := store.Load(name)
+	if err != nil {
+		return err
+	}
+	forecasts, err := store.LoadForecasts(name)
+	if err != nil {
+		return err
+	}
+
+	since := time.Now().AddDate(0, 0, -cmd.last).Unix()
+	var recent []observations.Forecast
+	for _, f := range forecasts {
+		if f.Issued >= since {
+			recent = append(recent, f)
+		}
+	}
+	scores := observations.Score(recent, obs)
+
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		for _, score := range scores {
+			if err := enc.Encode(score); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	conf, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	system := displayUnits(conf)
+
+	return observations.PrintAccuracy(os.Stdout, name, scores, system)
+}
@@ -0,0 +1,77 @@
+// Package astro calculates the times of the sun and the moon and where
+// they are in the sky from the coordinates of a location, without the
+// network. The times are accurate to a minute or two, enough for a
+// forecast but not for navigation.
+package astro
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	rad = math.Pi / 180
+
+	// j2000 is the Julian day of the epoch 2000-01-01 12:00 UTC.
+	j2000 = 2451545.0
+	// unixEpoch is the Julian day of 1970-01-01 00:00 UTC.
+	unixEpoch = 2440587.5
+
+	// obliquity is the tilt of the earth's axis in degrees.
+	obliquity = 23.4397
+)
+
+// julianDay returns the Julian day of the time.
+func julianDay(t time.Time) float64 {
+	return float64(t.Unix())/86400 + unixEpoch
+}
+
+// fromJulianDay returns the time of the Julian day in UTC.
+func fromJulianDay(jd float64) time.Time {
+	return time.Unix(int64(math.Round((jd-unixEpoch)*86400)), 0).UTC()
+}
+
+// daysSinceJ2000 returns the days since the J2000 epoch.
+func daysSinceJ2000(t time.Time) float64 {
+	return julianDay(t) - j2000
+}
+
+// normalize returns the angle in degrees between 0 and 360.
+func normalize(degrees float64) float64 {
+	degrees = math.Mod(degrees, 360)
+	if degrees < 0 {
+		degrees += 360
+	}
+	return degrees
+}
+
+// equatorial converts the ecliptic longitude and latitude in degrees to
+// the right ascension and declination in degrees.
+func equatorial(longitude, latitude float64) (rightAscension, declination float64) {
+	l, b, e := longitude*rad, latitude*rad, obliquity*rad
+	rightAscension = math.Atan2(math.Sin(l)*math.Cos(e)-math.Tan(b)*math.Sin(e), math.Cos(l)) / rad
+	declination = math.Asin(math.Sin(b)*math.Cos(e)+math.Cos(b)*math.Sin(e)*math.Sin(l)) / rad
+	return rightAscension, declination
+}
+
+// siderealTime returns the local sidereal time in degrees d days since
+// J2000 at the longitude in degrees.
+func siderealTime(d, lng float64) float64 {
+	return 280.16 + 360.9856235*d + lng
+}
+
+// horizontal returns the position in the sky of a body at the right
+// ascension and declination in degrees, from the latitude and longitude
+// in degrees d days since J2000.
+func horizontal(d, lat, lng, rightAscension, declination float64) Position {
+	h := (siderealTime(d, lng) - rightAscension) * rad
+	phi, dec := lat*rad, declination*rad
+
+	altitude := math.Asin(math.Sin(phi)*math.Sin(dec) + math.Cos(phi)*math.Cos(dec)*math.Cos(h))
+	// measured from south, turned to be from north
+	azimuth := math.Atan2(math.Sin(h), math.Cos(h)*math.Sin(phi)-math.Tan(dec)*math.Cos(phi))
+	return Position{
+		Altitude: altitude / rad,
+		Azimuth:  normalize(azimuth/rad + 180),
+	}
+}
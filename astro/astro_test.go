@@ -0,0 +1,334 @@
+package astro
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// tolerance is how far the times may be from the almanac's, which are
+// rounded to the minute.
+const tolerance = 2 * time.Minute
+
+// The expected times are from the published sunrise, sunset and twilight
+// tables for the places, in their local time. An empty time means the
+// event does not happen that day.
+func TestSun(t *testing.T) {
+	testCases := []struct {
+		name     string
+		lat, lng float64
+		zone     string
+		date     string
+		// the dawns and dusks, from astronomical to the sunrise and sunset
+		astronomical, nautical, civil, sun [2]string
+		noon                               string
+	}{
+		{
+			name: "London summer solstice", lat: 51.5074, lng: -0.1278, zone: "Europe/London", date: "2024-06-21",
+			astronomical: [2]string{"", ""}, nautical: [2]string{"02:41", "23:24"}, civil: [2]string{"03:55", "22:09"}, sun: [2]string{"04:43", "21:21"},
+			noon: "13:02",
+		},
+		{
+			name: "London winter solstice", lat: 51.5074, lng: -0.1278, zone: "Europe/London", date: "2024-12-21",
+			astronomical: [2]string{"06:00", "17:58"}, nautical: [2]string{"06:40", "17:17"}, civil: [2]string{"07:24", "16:34"}, sun: [2]string{"08:04", "15:53"},
+			noon: "11:59",
+		},
+		{
+			name: "New York summer solstice", lat: 40.7128, lng: -74.006, zone: "America/New_York", date: "2024-06-20",
+			astronomical: [2]string{"03:18", "22:37"}, nautical: [2]string{"04:09", "21:47"}, civil: [2]string{"04:52", "21:04"}, sun: [2]string{"05:25", "20:31"},
+			noon: "12:58",
+		},
+		{
+			name: "Sydney summer solstice", lat: -33.8688, lng: 151.2093, zone: "Australia/Sydney", date: "2024-12-21",
+			astronomical: [2]string{"03:57", "21:50"}, nautical: [2]string{"04:36", "21:10"}, civil: [2]string{"05:12", "20:35"}, sun: [2]string{"05:41", "20:05"},
+			noon: "12:53",
+		},
+		{
+			// the equation of time is at its most negative and positive
+			name: "Greenwich in February", lat: 51.4779, lng: 0, zone: "UTC", date: "2024-02-11",
+			astronomical: [2]string{"05:30", "18:59"}, nautical: [2]string{"06:08", "18:20"}, civil: [2]string{"06:48", "17:41"}, sun: [2]string{"07:23", "17:06"},
+			noon: "12:14",
+		},
+		{
+			name: "Greenwich in November", lat: 51.4779, lng: 0, zone: "UTC", date: "2024-11-03",
+			astronomical: [2]string{"05:04", "18:23"}, nautical: [2]string{"05:42", "17:45"}, civil: [2]string{"06:22", "17:05"}, sun: [2]string{"06:57", "16:30"},
+			noon: "11:44",
+		},
+		{
+			name: "Tromsø polar day", lat: 69.6492, lng: 18.9553, zone: "Europe/Oslo", date: "2024-06-21",
+			noon: "12:46",
+		},
+		{
+			name: "Tromsø polar night", lat: 69.6492, lng: 18.9553, zone: "Europe/Oslo", date: "2024-12-21",
+			astronomical: [2]string{"06:29", "16:56"}, nautical: [2]string{"07:47", "15:38"}, civil: [2]string{"09:31", "13:53"},
+			noon: "11:42",
+		},
+	}
+
+	for _, tc := range testCases {
+		loc, err := time.LoadLocation(tc.zone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		date, err := time.ParseInLocation("2006-01-02", tc.date, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		check := func(event string, got time.Time, expected string) {
+			if expected == "" {
+				if !got.IsZero() {
+					t.Errorf("%s: expected no %s, got %s", tc.name, event, got.In(loc).Format("15:04:05"))
+				}
+				return
+			}
+			want, err := time.ParseInLocation("2006-01-02 15:04", tc.date+" "+expected, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.IsZero() {
+				t.Errorf("%s: expected %s at %s, got none", tc.name, event, expected)
+				return
+			}
+			if d := got.Sub(want); d < -tolerance || d > tolerance {
+				t.Errorf("%s: expected %s at %s, got %s", tc.name, event, expected, got.In(loc).Format("15:04:05"))
+			}
+		}
+
+		sun := Sun(date, tc.lat, tc.lng)
+		check("astronomical dawn", sun.AstronomicalDawn, tc.astronomical[0])
+		check("nautical dawn", sun.NauticalDawn, tc.nautical[0])
+		check("civil dawn", sun.CivilDawn, tc.civil[0])
+		check("sunrise", sun.Sunrise, tc.sun[0])
+		check("solar noon", sun.SolarNoon, tc.noon)
+		check("sunset", sun.Sunset, tc.sun[1])
+		check("civil dusk", sun.CivilDusk, tc.civil[1])
+		check("nautical dusk", sun.NauticalDusk, tc.nautical[1])
+		check("astronomical dusk", sun.AstronomicalDusk, tc.astronomical[1])
+
+		// Sunrise agrees with the sunrise of Sun
+		rise, set, ok := Sunrise(date, tc.lat, tc.lng)
+		if ok != !sun.Sunrise.IsZero() || !rise.Equal(sun.Sunrise) || !set.Equal(sun.Sunset) {
+			t.Errorf("%s: Sunrise returned %s, %s, %t, different from Sun", tc.name, rise, set, ok)
+		}
+	}
+}
+
+func TestNoonAltitude(t *testing.T) {
+	testCases := []struct {
+		name     string
+		lat, lng float64
+		date     string
+		// the altitude is within a degree of expected
+		expected float64
+	}{
+		// 90 - latitude + the declination of the sun at the solstices
+		{name: "Tromsø polar day", lat: 69.6492, lng: 18.9553, date: "2024-06-21", expected: 43.8},
+		{name: "Tromsø polar night", lat: 69.6492, lng: 18.9553, date: "2024-12-21", expected: -3.1},
+		{name: "Longyearbyen polar night", lat: 78.2232, lng: 15.6267, date: "2024-12-21", expected: -11.7},
+		{name: "equator at the equinox", lat: 0, lng: 0, date: "2024-03-20", expected: 90},
+	}
+
+	for _, tc := range testCases {
+		date, err := time.Parse("2006-01-02", tc.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := NoonAltitude(date, tc.lat, tc.lng); math.Abs(got-tc.expected) > 1 {
+			t.Errorf("%s: expected the sun %.1f° high at noon, got %.1f°", tc.name, tc.expected, got)
+		}
+	}
+
+	// there is no civil twilight in the polar night of Longyearbyen, only
+	// a short nautical one
+	date := time.Date(2024, time.December, 21, 0, 0, 0, 0, time.UTC)
+	sun := Sun(date, 78.2232, 15.6267)
+	if !sun.Sunrise.IsZero() || !sun.CivilDawn.IsZero() {
+		t.Errorf("expected no sunrise or civil twilight in Longyearbyen, got %+v", sun)
+	}
+	if sun.NauticalDawn.IsZero() || sun.AstronomicalDawn.IsZero() {
+		t.Errorf("expected nautical and astronomical twilight in Longyearbyen, got %+v", sun)
+	}
+}
+
+// The expected positions are from the solar position tables for the
+// places, in degrees.
+func TestSunPosition(t *testing.T) {
+	testCases := []struct {
+		name     string
+		lat, lng float64
+		time     string
+		// the altitude and azimuth are within a degree of expected
+		altitude, azimuth float64
+	}{
+		{name: "Greenwich summer solstice", lat: 51.4769, lng: 0, time: "2024-06-21T12:00:00Z", altitude: 62.0, azimuth: 179.0},
+		{name: "Greenwich winter solstice", lat: 51.4769, lng: 0, time: "2024-12-21T12:00:00Z", altitude: 15.1, azimuth: 180.1},
+		{name: "equator at the equinox", lat: 0, lng: 0, time: "2024-03-20T12:07:00Z", altitude: 89.8, azimuth: 93.6},
+		// the sun is to the north at noon in the south
+		{name: "Sydney summer solstice", lat: -33.8688, lng: 151.2093, time: "2024-12-21T01:53:00Z", altitude: 79.6, azimuth: 0},
+		{name: "New York sunrise", lat: 40.7128, lng: -74.006, time: "2024-06-20T09:25:00Z", altitude: -0.8, azimuth: 57.5},
+		{name: "New York sunset", lat: 40.7128, lng: -74.006, time: "2024-06-21T00:31:00Z", altitude: -0.8, azimuth: 302.4},
+		{name: "New York midnight", lat: 40.7128, lng: -74.006, time: "2024-06-20T04:58:00Z", altitude: -25.7, azimuth: 0},
+	}
+
+	for _, tc := range testCases {
+		at, err := time.Parse(time.RFC3339, tc.time)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := SunPosition(at, tc.lat, tc.lng)
+		if math.Abs(got.Altitude-tc.altitude) > 1 {
+			t.Errorf("%s: expected the sun %.1f° high, got %.1f°", tc.name, tc.altitude, got.Altitude)
+		}
+		// the azimuth wraps around at north
+		if d := math.Abs(got.Azimuth - tc.azimuth); math.Min(d, 360-d) > 1 {
+			t.Errorf("%s: expected the sun at %.1f°, got %.1f°", tc.name, tc.azimuth, got.Azimuth)
+		}
+	}
+
+	// the sun is at the altitude of NoonAltitude at solar noon, and due
+	// south or north
+	for _, tc := range []struct {
+		lat, lng float64
+		azimuth  float64
+	}{{51.5074, -0.1278, 180}, {-33.8688, 151.2093, 0}} {
+		date := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+		noon := Sun(date, tc.lat, tc.lng).SolarNoon
+		got := SunPosition(noon, tc.lat, tc.lng)
+		if want := NoonAltitude(date, tc.lat, tc.lng); math.Abs(got.Altitude-want) > 0.1 {
+			t.Errorf("%v, %v: expected the sun %.1f° high at noon, got %.1f°", tc.lat, tc.lng, want, got.Altitude)
+		}
+		if d := math.Abs(got.Azimuth - tc.azimuth); math.Min(d, 360-d) > 0.5 {
+			t.Errorf("%v, %v: expected the sun at %.0f° at noon, got %.1f°", tc.lat, tc.lng, tc.azimuth, got.Azimuth)
+		}
+	}
+}
+
+// The expected phases are at the published times of the new moons, full
+// moons and quarters.
+func TestMoonPhase(t *testing.T) {
+	testCases := []struct {
+		time     string
+		phase    float64
+		fraction float64
+		name     string
+	}{
+		{time: "2024-01-04T03:30:00Z", phase: LastQuarter, fraction: 0.5, name: "Last quarter"},
+		{time: "2024-01-18T03:52:00Z", phase: FirstQuarter, fraction: 0.5, name: "First quarter"},
+		{time: "2024-04-08T18:21:00Z", phase: NewMoon, fraction: 0, name: "New moon"},
+		{time: "2024-04-23T23:49:00Z", phase: FullMoon, fraction: 1, name: "Full moon"},
+		{time: "2024-09-18T02:34:00Z", phase: FullMoon, fraction: 1, name: "Full moon"},
+		{time: "2024-10-02T18:49:00Z", phase: NewMoon, fraction: 0, name: "New moon"},
+	}
+
+	for _, tc := range testCases {
+		at, err := time.Parse(time.RFC3339, tc.time)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		phase := MoonPhase(at)
+		// the phase wraps around at the new moon
+		if d := math.Abs(phase - tc.phase); math.Min(d, 1-d) > 0.01 {
+			t.Errorf("%s: expected phase %.2f, got %.3f", tc.time, tc.phase, phase)
+		}
+		if got := MoonIllumination(at).Fraction; math.Abs(got-tc.fraction) > 0.02 {
+			t.Errorf("%s: expected %.0f%% lit, got %.1f%%", tc.time, tc.fraction*100, got*100)
+		}
+		if got := PhaseName(phase); got != tc.name {
+			t.Errorf("%s: expected %q, got %q", tc.time, tc.name, got)
+		}
+	}
+}
+
+func TestPhaseGlyph(t *testing.T) {
+	testCases := map[float64]string{
+		0:     "○",
+		0.02:  "○",
+		0.125: "☽",
+		0.25:  "◑",
+		0.5:   "●",
+		0.75:  "◐",
+		0.9:   "☾",
+		0.98:  "○",
+	}
+
+	for phase, expected := range testCases {
+		if got := PhaseGlyph(phase); got != expected {
+			t.Errorf("PhaseGlyph(%v): expected %q, got %q", phase, expected, got)
+		}
+	}
+}
+
+// The moon is worked out from the largest terms of its orbit only, so its
+// times are within moonTolerance of the almanac's.
+const moonTolerance = 10 * time.Minute
+
+// The expected times are from the almanac for the places, in their local
+// time. An empty time means the moon does not rise or set that day.
+func TestMoonRiseSet(t *testing.T) {
+	testCases := []struct {
+		name     string
+		lat, lng float64
+		zone     string
+		date     string
+		rise     string
+		set      string
+		// the moon is above or below the horizon all day
+		up, down bool
+	}{
+		// the full moon rises as the sun sets at 19:43
+		{name: "New York full moon", lat: 40.7128, lng: -74.006, zone: "America/New_York", date: "2024-04-23", rise: "19:48", set: "05:40"},
+		// the moon eclipsing the sun rises and sets with it, at 06:27 and
+		// 19:27
+		{name: "New York eclipse", lat: 40.7128, lng: -74.006, zone: "America/New_York", date: "2024-04-08", rise: "06:32", set: "19:40"},
+		{name: "London new year", lat: 51.5074, lng: -0.1278, zone: "Europe/London", date: "2024-01-01", rise: "22:03", set: "11:02"},
+		// the moon rises after midnight, on the next day
+		{name: "London no moonrise", lat: 51.5074, lng: -0.1278, zone: "Europe/London", date: "2024-01-03", set: "11:21"},
+		{name: "London harvest moon", lat: 51.5074, lng: -0.1278, zone: "Europe/London", date: "2024-09-18", rise: "19:26", set: "06:45"},
+		// the moon is far south in the major lunar standstill, so the
+		// summer full moon never rises and the winter one never sets
+		{name: "Tromsø summer full moon", lat: 69.6492, lng: 18.9553, zone: "Europe/Oslo", date: "2024-06-21", down: true},
+		{name: "Tromsø winter full moon", lat: 69.6492, lng: 18.9553, zone: "Europe/Oslo", date: "2024-12-15", up: true},
+		{name: "Tromsø polar night", lat: 69.6492, lng: 18.9553, zone: "Europe/Oslo", date: "2024-12-21", rise: "22:12", set: "12:05"},
+	}
+
+	for _, tc := range testCases {
+		loc, err := time.LoadLocation(tc.zone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		date, err := time.ParseInLocation("2006-01-02", tc.date, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		check := func(event string, got time.Time, expected string) {
+			if expected == "" {
+				if !got.IsZero() {
+					t.Errorf("%s: expected no %s, got %s", tc.name, event, got.In(loc).Format("15:04:05"))
+				}
+				return
+			}
+			want, err := time.ParseInLocation("2006-01-02 15:04", tc.date+" "+expected, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.IsZero() {
+				t.Errorf("%s: expected %s at %s, got none", tc.name, event, expected)
+				return
+			}
+			if d := got.Sub(want); d < -moonTolerance || d > moonTolerance {
+				t.Errorf("%s: expected %s at %s, got %s", tc.name, event, expected, got.In(loc).Format("15:04:05"))
+			}
+		}
+
+		moon := MoonRiseSet(date, tc.lat, tc.lng)
+		check("moonrise", moon.Rise, tc.rise)
+		check("moonset", moon.Set, tc.set)
+		if moon.AlwaysUp != tc.up || moon.AlwaysDown != tc.down {
+			t.Errorf("%s: expected the moon always up %t and down %t, got %t and %t", tc.name, tc.up, tc.down, moon.AlwaysUp, moon.AlwaysDown)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package astro
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// MoonriseAltitude is the altitude in degrees of the centre of the
+	// moon when it rises, accounting for refraction and its radius.
+	MoonriseAltitude = 0.133
+
+	// sunDistance is the mean distance to the sun in kilometers.
+	sunDistance = 149598000
+)
+
+// Phases of the moon, as the fraction of the lunation.
+const (
+	NewMoon      = 0.0
+	FirstQuarter = 0.25
+	FullMoon     = 0.5
+	LastQuarter  = 0.75
+)
+
+// phaseNames are the names of the eight phases of the moon, starting at
+// the new moon.
+var phaseNames = []string{
+	"New moon",
+	"Waxing crescent",
+	"First quarter",
+	"Waxing gibbous",
+	"Full moon",
+	"Waning gibbous",
+	"Last quarter",
+	"Waning crescent",
+}
+
+// phaseGlyphs are the symbols for the eight phases of the moon, starting
+// at the new moon, with the lit part filled in.
+var phaseGlyphs = []string{"○", "☽", "◑", "◑", "●", "◐", "◐", "☾"}
+
+// Illumination is how much of the moon is lit.
+type Illumination struct {
+	// Fraction is the lit fraction of the disc, from 0 to 1.
+	Fraction float64
+	// Phase is the fraction of the lunation, from 0 at the new moon
+	// through 0.5 at the full moon to 1.
+	Phase float64
+}
+
+// MoonTimes are when the moon rises and sets on a day. Rise or Set are zero
+// if the moon does not rise or set that day.
+type MoonTimes struct {
+	Rise time.Time
+	Set  time.Time
+	// AlwaysUp and AlwaysDown are true if the moon is above or below the
+	// horizon all day.
+	AlwaysUp   bool
+	AlwaysDown bool
+}
+
+// moonEcliptic returns the ecliptic longitude and latitude of the moon in
+// degrees and its distance in kilometers d days since J2000.
+func moonEcliptic(d float64) (longitude, latitude, distance float64) {
+	meanLongitude := 218.316 + 13.176396*d
+	meanAnomaly := 134.963 + 13.064993*d
+	argumentOfLatitude := 93.272 + 13.229350*d
+
+	longitude = normalize(meanLongitude + 6.289*math.Sin(meanAnomaly*rad))
+	latitude = 5.128 * math.Sin(argumentOfLatitude*rad)
+	distance = 385001 - 20905*math.Cos(meanAnomaly*rad)
+	return longitude, latitude, distance
+}
+
+// moonCoordinates returns the right ascension and declination of the moon
+// in degrees and its distance in kilometers d days since J2000.
+func moonCoordinates(d float64) (rightAscension, declination, distance float64) {
+	longitude, latitude, distance := moonEcliptic(d)
+	rightAscension, declination = equatorial(longitude, latitude)
+	return rightAscension, declination, distance
+}
+
+// MoonPosition returns the position of the moon at the time from the
+// latitude and longitude in degrees, corrected for parallax.
+func MoonPosition(t time.Time, lat, lng float64) Position {
+	d := daysSinceJ2000(t)
+	rightAscension, declination, distance := moonCoordinates(d)
+	p := horizontal(d, lat, lng, rightAscension, declination)
+
+	// the moon is close enough that where on the earth it is seen from
+	// lowers it in the sky
+	p.Altitude -= math.Asin(6378.14/distance) / rad * math.Cos(p.Altitude*rad)
+	return p
+}
+
+// MoonIllumination returns how much of the moon is lit at the time.
+func MoonIllumination(t time.Time) Illumination {
+	d := daysSinceJ2000(t)
+	sunRA, sunDec := sunCoordinates(d)
+	moonRA, moonDec, distance := moonCoordinates(d)
+
+	sDec, mDec, dRA := sunDec*rad, moonDec*rad, (sunRA-moonRA)*rad
+
+	// the angle between the sun and the moon seen from the earth, and the
+	// angle between the sun and the earth seen from the moon
+	elongation := math.Acos(math.Sin(sDec)*math.Sin(mDec) + math.Cos(sDec)*math.Cos(mDec)*math.Cos(dRA))
+	inclination := math.Atan2(sunDistance*math.Sin(elongation), distance-sunDistance*math.Cos(elongation))
+
+	// the phase follows the difference in longitude, which unlike the
+	// lit fraction grows steadily through the lunation
+	moonLongitude, _, _ := moonEcliptic(d)
+	sunLongitude := sunEclipticLongitude(sunMeanAnomaly(d))
+	return Illumination{
+		Fraction: (1 + math.Cos(inclination)) / 2,
+		Phase:    normalize(moonLongitude-sunLongitude) / 360,
+	}
+}
+
+// MoonPhase returns the phase of the moon at the time as the fraction of
+// the lunation, from 0 at the new moon through 0.5 at the full moon to 1,
+// the same as Dark Sky's moonPhase.
+func MoonPhase(t time.Time) float64 {
+	return MoonIllumination(t).Phase
+}
+
+// MoonRiseSet returns when the moon rises and sets on the date in its
+// location, from the latitude and longitude in degrees.
+func MoonRiseSet(date time.Time, lat, lng float64) MoonTimes {
+	y, m, d := date.Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, date.Location())
+	end := start.AddDate(0, 0, 1)
+
+	// step through the day looking for the moon crossing the horizon,
+	// interpolating between the steps
+	const step = 10 * time.Minute
+	var times MoonTimes
+	above := false
+	prev := MoonPosition(start, lat, lng).Altitude - MoonriseAltitude
+	for t := start.Add(step); !t.After(end); t = t.Add(step) {
+		alt := MoonPosition(t, lat, lng).Altitude - MoonriseAltitude
+		if alt > 0 || prev > 0 {
+			above = true
+		}
+		if (prev < 0) != (alt < 0) {
+			crossing := t.Add(-time.Duration(float64(step) * alt / (alt - prev))).Round(time.Second)
+			if prev < 0 && times.Rise.IsZero() {
+				times.Rise = crossing
+			}
+			if prev >= 0 && times.Set.IsZero() {
+				times.Set = crossing
+			}
+		}
+		prev = alt
+	}
+
+	if times.Rise.IsZero() && times.Set.IsZero() {
+		times.AlwaysUp = above
+		times.AlwaysDown = !above
+	}
+	return times
+}
+
+// PhaseName returns the name of the phase of the moon, e.g. "Waxing
+// crescent".
+func PhaseName(phase float64) string {
+	return phaseNames[phaseIndex(phase)]
+}
+
+// PhaseGlyph returns the symbol for the phase of the moon, e.g. "☽".
+func PhaseGlyph(phase float64) string {
+	return phaseGlyphs[phaseIndex(phase)]
+}
+
+// phaseIndex returns which of the eight phases the fraction of the
+// lunation is in, each centred on its exact phase.
+func phaseIndex(phase float64) int {
+	return int(math.Floor(phase*8+0.5)) % 8
+}
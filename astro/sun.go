@@ -0,0 +1,153 @@
+package astro
+
+import (
+	"math"
+	"time"
+)
+
+// Altitudes of the centre of the sun in degrees for the events of the day.
+const (
+	// SunriseAltitude accounts for refraction and the radius of the sun
+	// so the upper edge is on the horizon.
+	SunriseAltitude = -0.833
+	// GoldenHourAltitude is the altitude below which the light is soft
+	// and warm in the golden hour after sunrise and before sunset.
+	GoldenHourAltitude = 6.0
+	// CivilTwilightAltitude is the end of civil twilight, when it is too
+	// dark to be outside without lights.
+	CivilTwilightAltitude = -6.0
+	// NauticalTwilightAltitude is the end of nautical twilight, when the
+	// horizon can no longer be seen at sea.
+	NauticalTwilightAltitude = -12.0
+	// AstronomicalTwilightAltitude is the end of astronomical twilight,
+	// when the sky is fully dark.
+	AstronomicalTwilightAltitude = -18.0
+)
+
+// Position is where a body is in the sky.
+type Position struct {
+	// Altitude is the angle in degrees above the horizon.
+	Altitude float64
+	// Azimuth is the angle in degrees clockwise from north.
+	Azimuth float64
+}
+
+// SunTimes are the times of the events of the sun on a day. Events that do
+// not happen that day, as in the polar summer or winter, are zero.
+type SunTimes struct {
+	AstronomicalDawn time.Time
+	NauticalDawn     time.Time
+	CivilDawn        time.Time
+	Sunrise          time.Time
+	GoldenHourEnd    time.Time
+	SolarNoon        time.Time
+	GoldenHour       time.Time
+	Sunset           time.Time
+	CivilDusk        time.Time
+	NauticalDusk     time.Time
+	AstronomicalDusk time.Time
+}
+
+// sunMeanAnomaly returns the mean anomaly of the sun in degrees d days
+// since J2000.
+func sunMeanAnomaly(d float64) float64 {
+	return normalize(357.5291 + 0.98560028*d)
+}
+
+// sunEclipticLongitude returns the ecliptic longitude of the sun in
+// degrees for the mean anomaly m.
+func sunEclipticLongitude(m float64) float64 {
+	center := 1.9148*math.Sin(m*rad) + 0.02*math.Sin(2*m*rad) + 0.0003*math.Sin(3*m*rad)
+	// 102.9372 is the perihelion of the earth
+	return normalize(m + center + 180 + 102.9372)
+}
+
+// sunDeclination returns the declination of the sun in degrees for the
+// ecliptic longitude l.
+func sunDeclination(l float64) float64 {
+	return math.Asin(math.Sin(l*rad)*math.Sin(obliquity*rad)) / rad
+}
+
+// sunCoordinates returns the right ascension and declination of the sun
+// in degrees d days since J2000.
+func sunCoordinates(d float64) (rightAscension, declination float64) {
+	return equatorial(sunEclipticLongitude(sunMeanAnomaly(d)), 0)
+}
+
+// transit returns the Julian day of the solar noon on the date in its
+// location, with the declination of the sun at the time.
+func transit(date time.Time, lng float64) (jd, declination float64) {
+	// the mean solar noon at the longitude on the date
+	y, m, d := date.Date()
+	noon := daysSinceJ2000(time.Date(y, m, d, 12, 0, 0, 0, time.UTC)) - lng/360
+
+	anomaly := sunMeanAnomaly(noon)
+	l := sunEclipticLongitude(anomaly)
+	jd = j2000 + noon + 0.0053*math.Sin(anomaly*rad) - 0.0069*math.Sin(2*l*rad)
+	return jd, sunDeclination(l)
+}
+
+// SunRiseSet returns when the centre of the sun rises above and sets below
+// the altitude in degrees on the date in its location, at the latitude and
+// longitude in degrees. It returns false if the sun does not cross the
+// altitude that day, as in the polar summer or winter.
+func SunRiseSet(date time.Time, lat, lng, altitude float64) (rise, set time.Time, ok bool) {
+	jd, declination := transit(date, lng)
+
+	cos := (math.Sin(altitude*rad) - math.Sin(lat*rad)*math.Sin(declination*rad)) / (math.Cos(lat*rad) * math.Cos(declination*rad))
+	if cos < -1 || cos > 1 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	hourAngle := math.Acos(cos) / rad
+	return fromJulianDay(jd - hourAngle/360), fromJulianDay(jd + hourAngle/360), true
+}
+
+// Sunrise returns the sunrise and sunset on the date in its location, or
+// false if the sun does not rise or set that day.
+func Sunrise(date time.Time, lat, lng float64) (sunrise, sunset time.Time, ok bool) {
+	return SunRiseSet(date, lat, lng, SunriseAltitude)
+}
+
+// SolarNoon returns when the sun is highest on the date in its location.
+func SolarNoon(date time.Time, lng float64) time.Time {
+	jd, _ := transit(date, lng)
+	return fromJulianDay(jd)
+}
+
+// NoonAltitude returns the altitude in degrees of the centre of the sun at
+// solar noon on the date in its location. When SunRiseSet returns false it
+// tells if the sun is above or below the altitude all day.
+func NoonAltitude(date time.Time, lat, lng float64) float64 {
+	_, declination := transit(date, lng)
+	return 90 - math.Abs(lat-declination)
+}
+
+// Sun returns the times of the events of the sun on the date in its
+// location.
+func Sun(date time.Time, lat, lng float64) SunTimes {
+	times := SunTimes{SolarNoon: SolarNoon(date, lng)}
+	for _, event := range []struct {
+		altitude  float64
+		rise, set *time.Time
+	}{
+		{AstronomicalTwilightAltitude, &times.AstronomicalDawn, &times.AstronomicalDusk},
+		{NauticalTwilightAltitude, &times.NauticalDawn, &times.NauticalDusk},
+		{CivilTwilightAltitude, &times.CivilDawn, &times.CivilDusk},
+		{SunriseAltitude, &times.Sunrise, &times.Sunset},
+		{GoldenHourAltitude, &times.GoldenHourEnd, &times.GoldenHour},
+	} {
+		if rise, set, ok := SunRiseSet(date, lat, lng, event.altitude); ok {
+			*event.rise, *event.set = rise, set
+		}
+	}
+	return times
+}
+
+// SunPosition returns the position of the sun at the time from the
+// latitude and longitude in degrees.
+func SunPosition(t time.Time, lat, lng float64) Position {
+	d := daysSinceJ2000(t)
+	rightAscension, declination := sunCoordinates(d)
+	return horizontal(d, lat, lng, rightAscension, declination)
+}
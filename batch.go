@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/genuinetools/weather/config"
+	"github.com/genuinetools/weather/forecast"
+	"github.com/genuinetools/weather/geocode"
+	"github.com/mitchellh/colorstring"
+)
+
+// maxBatchRequests is the most forecast requests a batch sent to the server
+// can hold, and batchParallelism how many locations of a batch are looked
+// up at once, by the client and the server.
+const (
+	maxBatchRequests = 100
+	batchParallelism = 8
+)
+
+// locationsFlag holds the locations passed via -l, which can be repeated.
+type locationsFlag []string
+
+func (l *locationsFlag) String() string {
+	return strings.Join(*l, "; ")
+}
+
+func (l *locationsFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// readLocations returns the locations passed via -l, reading those of a
+// value of - from stdin and of @file from the file, a location per line.
+// Blank lines and lines starting with # are skipped.
+func readLocations(values []string) ([]string, error) {
+	var names []string
+	for _, value := range values {
+		var r io.Reader
+		switch {
+		case value == "-":
+			r = os.Stdin
+		case strings.HasPrefix(value, "@"):
+			f, err := os.Open(strings.TrimPrefix(value, "@"))
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			r = f
+		default:
+			names = append(names, value)
+			continue
+		}
+
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			names = append(names, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("reading locations from %s failed: %v", value, err)
+		}
+	}
+	return names, nil
+}
+
+// batchForecast is the forecast for one of the locations of a batch, or the
+// error getting it.
+type batchForecast struct {
+	name     string
+	geocode  geocode.Geocode
+	forecast forecast.Forecast
+	err      error
+}
+
+// getBatch returns the forecasts for the locations, in order. The locations
+// are looked up at once and their forecasts requested in one round trip to
+// the server, or at once if it takes no batches.
+func getBatch(names []string) ([]batchForecast, error) {
+	conf, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]batchForecast, len(names))
+	each(len(names), func(i int) {
+		results[i].name = names[i]
+		g, err := geocode.Locate(names[i], server)
+		switch {
+		case err != nil:
+			results[i].err = err
+		case g.Latitude == 0 || g.Longitude == 0:
+			results[i].err = fmt.Errorf("latitude and longitude could not be determined for %q", names[i])
+		default:
+			results[i].geocode = g
+		}
+	})
+
+	var located []*batchForecast
+	for i := range results {
+		if results[i].err == nil {
+			located = append(located, &results[i])
+		}
+	}
+
+	for start := 0; start < len(located); start += maxBatchRequests {
+		chunk := located[start:]
+		if len(chunk) > maxBatchRequests {
+			chunk = chunk[:maxBatchRequests]
+		}
+		if err := getBatchForecasts(conf, chunk); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, result := range located {
+		if result.err == nil {
+			result.forecast = convertUnits(conf, result.forecast)
+		}
+	}
+	return results, nil
+}
+
+// getBatchForecasts requests the forecasts for the located locations of a
+// batch from the server.
+func getBatchForecasts(conf config.Config, located []*batchForecast) error {
+	requests := make([]forecast.Request, len(located))
+	for i, result := range located {
+		requests[i] = forecastRequest(conf, result.geocode)
+	}
+
+	forecasts, err := forecast.GetBatch(fmt.Sprintf("%s/forecast/batch", server), requests)
+	if err == forecast.ErrNoBatch {
+		// an older server, so one request per location
+		each(len(located), func(i int) {
+			located[i].forecast, located[i].err = forecast.Get(fmt.Sprintf("%s/forecast", server), requests[i])
+		})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for i, result := range forecasts {
+		if result.Error != "" {
+			located[i].err = fmt.Errorf("Forecast API response error: %s", result.Error)
+			continue
+		}
+		located[i].forecast = *result.Forecast
+	}
+	return nil
+}
+
+// each calls fn for each index up to n, batchParallelism at once.
+func each(n int, fn func(i int)) {
+	sem := make(chan struct{}, batchParallelism)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// batchLine is a line of the JSON output of a batch.
+type batchLine struct {
+	Location string          `json:"location"`
+	Forecast json.RawMessage `json:"forecast,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// renderBatch writes the forecasts for the locations as a table comparing
+// them, or as a line of JSON for each.
+func renderBatch(w io.Writer, names []string) error {
+	results, err := getBatch(names)
+	if err != nil {
+		return err
+	}
+	opts, err := getOptions()
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		for _, result := range results {
+			line := batchLine{Location: result.name}
+			if result.err != nil {
+				line.Error = result.err.Error()
+			} else {
+				var b bytes.Buffer
+				if err := (forecast.JSONRenderer{}).Render(&b, result.forecast, result.geocode, opts); err != nil {
+					return err
+				}
+				line.Forecast = bytes.TrimSpace(b.Bytes())
+			}
+			if err := enc.Encode(line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var places []forecast.Comparison
+	for _, result := range results {
+		if result.err == nil {
+			places = append(places, forecast.Comparison{Place: result.name, Forecast: result.forecast})
+		}
+	}
+	if len(places) > 0 {
+		if err := forecast.PrintComparison(w, places, opts); err != nil {
+			return err
+		}
+	}
+	for _, result := range results {
+		if result.err != nil {
+			fmt.Fprintln(w, colorstring.Color(fmt.Sprintf("[red]%s: %v", result.name, result.err)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/genuinetools/weather/forecast"
+	"github.com/genuinetools/weather/geocode"
+)
+
+// testServer is a stand in for the weather server. Locations are looked up
+// as "lat,lng", the forecasts have the latitude requested, and the forecast
+// at a latitude of 3 is an error.
+type testServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	batches []int
+	singles int
+}
+
+func newTestServer(t *testing.T, batch bool) *testServer {
+	s := &testServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/geocode", func(w http.ResponseWriter, r *http.Request) {
+		var req geocode.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		var g geocode.Geocode
+		if _, err := fmt.Sscanf(req.Location, "%g,%g", &g.Latitude, &g.Longitude); err != nil {
+			g.Error = "No results found."
+		}
+		json.NewEncoder(w).Encode(g)
+	})
+	mux.HandleFunc("/forecast", func(w http.ResponseWriter, r *http.Request) {
+		var req forecast.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		s.mu.Lock()
+		s.singles++
+		s.mu.Unlock()
+		json.NewEncoder(w).Encode(testServerForecast(req))
+	})
+	if batch {
+		mux.HandleFunc("/forecast/batch", func(w http.ResponseWriter, r *http.Request) {
+			var requests []forecast.Request
+			json.NewDecoder(r.Body).Decode(&requests)
+			s.mu.Lock()
+			s.batches = append(s.batches, len(requests))
+			s.mu.Unlock()
+
+			results := make([]forecast.BatchResult, len(requests))
+			for i, req := range requests {
+				fc := testServerForecast(req)
+				results[i].Forecast = &fc
+			}
+			json.NewEncoder(w).Encode(results)
+		})
+	}
+	s.Server = httptest.NewServer(mux)
+
+	uri, path := server, configPath
+	t.Cleanup(func() {
+		server, configPath = uri, path
+		s.Close()
+	})
+	server = s.URL
+	configPath = filepath.Join(t.TempDir(), "config.json")
+	return s
+}
+
+func testServerForecast(req forecast.Request) forecast.Forecast {
+	if req.Latitude == 3 {
+		return forecast.Forecast{Error: "daily usage limit exceeded"}
+	}
+	return forecast.Forecast{Latitude: req.Latitude, Longitude: req.Longitude, Flags: forecast.Flags{Units: "si"}}
+}
+
+// batchSummary describes the results of a batch by the latitude of each
+// forecast or the error getting it.
+func batchSummary(results []batchForecast) []string {
+	summary := make([]string, len(results))
+	for i, result := range results {
+		summary[i] = fmt.Sprintf("%s: %g", result.name, result.forecast.Latitude)
+		if result.err != nil {
+			summary[i] = fmt.Sprintf("%s: %v", result.name, result.err)
+		}
+	}
+	return summary
+}
+
+func TestGetBatch(t *testing.T) {
+	names := []string{"1,1", "nowhere", "2,2", "3,3", "4,4", "0,7"}
+	expected := []string{
+		"1,1: 1",
+		"nowhere: Geocode API response error: No results found.",
+		"2,2: 2",
+		"3,3: Forecast API response error: daily usage limit exceeded",
+		"4,4: 4",
+		`0,7: latitude and longitude could not be determined for "0,7"`,
+	}
+
+	for _, batch := range []bool{true, false} {
+		s := newTestServer(t, batch)
+		results, err := getBatch(names)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if summary := batchSummary(results); !reflect.DeepEqual(summary, expected) {
+			t.Errorf("batch %t: expected\n%q\ngot\n%q", batch, expected, summary)
+		}
+
+		// the located locations are requested in a batch, or one at a
+		// time from a server without the batch endpoint
+		switch {
+		case batch && (!reflect.DeepEqual(s.batches, []int{4}) || s.singles != 0):
+			t.Errorf("expected a batch of 4, got batches of %v and %d single requests", s.batches, s.singles)
+		case !batch && s.singles != 4:
+			t.Errorf("expected 4 single requests to a server without batches, got %d", s.singles)
+		}
+	}
+}
+
+func TestGetBatchChunks(t *testing.T) {
+	s := newTestServer(t, true)
+
+	n := 2*maxBatchRequests + 50
+	names := make([]string, n)
+	expected := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("%d,1", i+10)
+		expected[i] = fmt.Sprintf("%d,1: %d", i+10, i+10)
+	}
+
+	results, err := getBatch(names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary := batchSummary(results); !reflect.DeepEqual(summary, expected) {
+		t.Errorf("expected the forecasts in order, got %q", summary)
+	}
+	if !reflect.DeepEqual(s.batches, []int{maxBatchRequests, maxBatchRequests, 50}) {
+		t.Errorf("expected batches of at most %d, got %v", maxBatchRequests, s.batches)
+	}
+}
+
+func TestReadLocations(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "locations")
+	if err := os.WriteFile(file, []byte("# home and away\nnew york\n\n  tokyo  \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stdin := filepath.Join(dir, "stdin")
+	if err := os.WriteFile(stdin, []byte("paris\n#berlin\nlondon"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(stdin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	defer func(r *os.File) { os.Stdin = r }(os.Stdin)
+	os.Stdin = f
+
+	names, err := readLocations([]string{"10028", "@" + file, "-", "sydney"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"10028", "new york", "tokyo", "paris", "london", "sydney"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %q, got %q", expected, names)
+	}
+
+	if _, err := readLocations([]string{"@" + filepath.Join(dir, "missing")}); err == nil || !strings.Contains(err.Error(), "no such file") {
+		t.Errorf("expected an error for a missing file, got %v", err)
+	}
+}
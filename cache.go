@@ -0,0 +1,120 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// maxPermanentEntries is the most responses that never change are cached,
+// the least recently used are dropped past it.
+const maxPermanentEntries = 1000
+
+// cacheEntry is a cached response from an upstream API.
+type cacheEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+// permanentEntry is a cached response that never changes.
+type permanentEntry struct {
+	key  string
+	body []byte
+}
+
+// responseCache holds successful upstream responses so repeated requests
+// for the same data do not use up the API quota.
+type responseCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	// permanent holds the responses that never change, with the most
+	// recently used at the front of recent.
+	permanent map[string]*list.Element
+	recent    *list.List
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{
+		ttl:       ttl,
+		entries:   map[string]cacheEntry{},
+		permanent: map[string]*list.Element{},
+		recent:    list.New(),
+	}
+}
+
+// get returns the cached body for the key if it has not expired.
+func (c *responseCache) get(key string) ([]byte, bool) {
+	if c == nil || c.ttl <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.permanent[key]; ok {
+		c.recent.MoveToFront(e)
+		return e.Value.(*permanentEntry).body, true
+	}
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return entry.body, true
+}
+
+// set caches the body for the key.
+func (c *responseCache) set(key string, body []byte) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	// drop anything that expired so the cache does not grow forever
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = cacheEntry{
+		body:    body,
+		expires: now.Add(c.ttl),
+	}
+}
+
+// setPermanent caches the body for the key without it expiring, for
+// responses that do not change like the weather in the past. Only the
+// maxPermanentEntries most recently used are kept, as the keys come from
+// the clients.
+func (c *responseCache) setPermanent(key string, body []byte) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.permanent[key]; ok {
+		e.Value.(*permanentEntry).body = body
+		c.recent.MoveToFront(e)
+		return
+	}
+
+	c.permanent[key] = c.recent.PushFront(&permanentEntry{key: key, body: body})
+	for c.recent.Len() > maxPermanentEntries {
+		oldest := c.recent.Back()
+		c.recent.Remove(oldest)
+		delete(c.permanent, oldest.Value.(*permanentEntry).key)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestResponseCachePermanent(t *testing.T) {
+	c := newResponseCache(time.Minute)
+
+	for i := 0; i < maxPermanentEntries; i++ {
+		c.setPermanent(fmt.Sprintf("day %d", i), []byte("weather"))
+	}
+	// using the first day keeps it over the second
+	if _, ok := c.get("day 0"); !ok {
+		t.Fatal("expected day 0 to be cached")
+	}
+	c.setPermanent("one more day", []byte("weather"))
+
+	if len(c.permanent) != maxPermanentEntries || c.recent.Len() != maxPermanentEntries {
+		t.Errorf("expected %d permanent entries, got %d", maxPermanentEntries, len(c.permanent))
+	}
+	for key, cached := range map[string]bool{"day 0": true, "day 1": false, "day 2": true, "one more day": true} {
+		if _, ok := c.get(key); ok != cached {
+			t.Errorf("expected %q to be cached %t, got %t", key, cached, ok)
+		}
+	}
+}
+
+func TestResponseCacheExpires(t *testing.T) {
+	c := newResponseCache(time.Minute)
+	c.set("now", []byte("weather"))
+	if body, ok := c.get("now"); !ok || string(body) != "weather" {
+		t.Fatalf("expected the response to be cached, got %q, %t", body, ok)
+	}
+
+	c.entries["now"] = cacheEntry{body: []byte("weather"), expires: time.Now().Add(-time.Second)}
+	if _, ok := c.get("now"); ok {
+		t.Error("expected the expired response not to be returned")
+	}
+	if _, ok := c.entries["now"]; ok {
+		t.Error("expected the expired response to be dropped")
+	}
+}
@@ -0,0 +1,250 @@
+package chart
+
+import (
+	"math"
+	"strings"
+
+	"github.com/mitchellh/colorstring"
+)
+
+// brailleDots are the bits of the braille pattern for each dot in a cell,
+// indexed by [y][x] with y counting down from the top of the cell.
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// barTicks are used to draw partial bars.
+var barTicks = []rune(" ▁▂▃▄▅▆▇█")
+
+// Series is a line to plot on a chart.
+type Series struct {
+	Values []float64
+	// Color is the colorstring color to draw the line in.
+	Color string
+	// Char is the character to draw the line with in ascii mode.
+	Char rune
+}
+
+// canvas is a grid of dots drawn with braille characters, 2x4 dots per
+// cell, or with plain characters, one dot per cell, in ascii mode.
+type canvas struct {
+	width, height int
+	ascii         bool
+
+	cells  [][]rune
+	series [][]int
+}
+
+func newCanvas(width, height int, ascii bool) *canvas {
+	c := &canvas{width: width, height: height, ascii: ascii}
+	c.cells = make([][]rune, height)
+	c.series = make([][]int, height)
+	for y := range c.cells {
+		c.cells[y] = make([]rune, width)
+		c.series[y] = make([]int, width)
+		for x := range c.series[y] {
+			c.series[y][x] = -1
+		}
+	}
+	return c
+}
+
+// dotsWide returns the number of dots across the canvas.
+func (c *canvas) dotsWide() int {
+	if c.ascii {
+		return c.width
+	}
+	return c.width * 2
+}
+
+// dotsHigh returns the number of dots up the canvas.
+func (c *canvas) dotsHigh() int {
+	if c.ascii {
+		return c.height
+	}
+	return c.height * 4
+}
+
+// set draws the dot at x, y counting from the bottom left for the series.
+func (c *canvas) set(x, y, series int, char rune) {
+	if x < 0 || y < 0 || x >= c.dotsWide() || y >= c.dotsHigh() {
+		return
+	}
+
+	y = c.dotsHigh() - 1 - y
+	if c.ascii {
+		c.cells[y][x] = char
+		c.series[y][x] = series
+		return
+	}
+
+	cx, cy := x/2, y/4
+	c.cells[cy][cx] |= brailleDots[y%4][x%2]
+	c.series[cy][cx] = series
+}
+
+// line draws a line between two dots.
+func (c *canvas) line(x0, y0, x1, y1, series int, char rune) {
+	steps := int(math.Max(math.Abs(float64(x1-x0)), math.Abs(float64(y1-y0))))
+	if steps == 0 {
+		c.set(x0, y0, series, char)
+		return
+	}
+	for i := 0; i <= steps; i++ {
+		x := x0 + int(math.Round(float64(i*(x1-x0))/float64(steps)))
+		y := y0 + int(math.Round(float64(i*(y1-y0))/float64(steps)))
+		c.set(x, y, series, char)
+	}
+}
+
+// rows returns the canvas as lines of text, colored by the series that
+// last drew in each cell.
+func (c *canvas) rows(colors []string) []string {
+	rows := make([]string, c.height)
+	for y := range c.cells {
+		var b strings.Builder
+		for x, r := range c.cells[y] {
+			if r == 0 {
+				b.WriteRune(' ')
+				continue
+			}
+			if !c.ascii {
+				// offset into the braille patterns block
+				r += 0x2800
+			}
+
+			s := c.series[y][x]
+			if s >= 0 && s < len(colors) && colors[s] != "" {
+				b.WriteString(colorstring.Color("[" + colors[s] + "]" + string(r)))
+				continue
+			}
+			b.WriteRune(r)
+		}
+		rows[y] = b.String()
+	}
+	return rows
+}
+
+// Line plots the series as lines scaled to their shared minimum and
+// maximum, which are returned for labelling the axis.
+func Line(series []Series, width, height int, ascii bool) (rows []string, min, max float64) {
+	min, max = bounds(series...)
+	c := newCanvas(width, height, ascii)
+
+	colors := make([]string, len(series))
+	for s, line := range series {
+		colors[s] = line.Color
+		prevX, prevY := -1, -1
+		for i, v := range line.Values {
+			x := scaleIndex(i, len(line.Values), c.dotsWide())
+			y := scaleValue(v, min, max, c.dotsHigh())
+			if prevX >= 0 {
+				c.line(prevX, prevY, x, y, s, line.Char)
+			} else {
+				c.set(x, y, s, line.Char)
+			}
+			prevX, prevY = x, y
+		}
+	}
+
+	if ascii {
+		colors = nil
+	}
+	return c.rows(colors), min, max
+}
+
+// Bands plots a filled band between the low and high value of each entry,
+// scaled to their minimum and maximum which are returned for labelling the
+// axis.
+func Bands(low, high []float64, color string, width, height int, ascii bool) (rows []string, min, max float64) {
+	min, max = bounds(Series{Values: low}, Series{Values: high})
+	c := newCanvas(width, height, ascii)
+
+	n := len(low)
+	if len(high) < n {
+		n = len(high)
+	}
+	for x := 0; x < c.dotsWide() && n > 0; x++ {
+		i := x * n / c.dotsWide()
+		// leave a gap between the entries so they can be told apart
+		if c.dotsWide()/n > 2 && (x+1)*n/c.dotsWide() != i {
+			continue
+		}
+		c.line(x, scaleValue(low[i], min, max, c.dotsHigh()), x, scaleValue(high[i], min, max, c.dotsHigh()), 0, '|')
+	}
+
+	colors := []string{color}
+	if ascii {
+		colors = nil
+	}
+	return c.rows(colors), min, max
+}
+
+// Bars draws a vertical bar for each value between 0 and max.
+func Bars(values []float64, max float64, color string, width, height int, ascii bool) []string {
+	rows := make([]string, height)
+	if len(values) == 0 || max <= 0 {
+		return rows
+	}
+
+	for y := 0; y < height; y++ {
+		var b strings.Builder
+		for x := 0; x < width; x++ {
+			v := values[x*len(values)/width]
+			// the number of eighths of a row that are filled from the bottom
+			level := int(math.Round(v/max*float64(height)*8)) - (height-1-y)*8
+			switch {
+			case level <= 0:
+				b.WriteRune(' ')
+			case ascii && level >= 4:
+				b.WriteRune('#')
+			case ascii:
+				b.WriteRune('.')
+			case level >= 8:
+				b.WriteRune(barTicks[8])
+			default:
+				b.WriteRune(barTicks[level])
+			}
+		}
+		rows[y] = b.String()
+		if !ascii && color != "" {
+			rows[y] = colorstring.Color("[" + color + "]" + rows[y])
+		}
+	}
+	return rows
+}
+
+// bounds returns the minimum and maximum of all the values, padded so a
+// flat line is still drawn in the middle of the chart.
+func bounds(series ...Series) (min, max float64) {
+	min, max = math.Inf(1), math.Inf(-1)
+	for _, s := range series {
+		for _, v := range s.Values {
+			min = math.Min(min, v)
+			max = math.Max(max, v)
+		}
+	}
+	if math.IsInf(min, 0) {
+		return 0, 1
+	}
+	if max-min < 1 {
+		min, max = min-0.5, max+0.5
+	}
+	return math.Floor(min), math.Ceil(max)
+}
+
+// scaleIndex maps entry i of n to a dot across a canvas dots wide.
+func scaleIndex(i, n, dots int) int {
+	if n < 2 {
+		return 0
+	}
+	return int(math.Round(float64(i) * float64(dots-1) / float64(n-1)))
+}
+
+// scaleValue maps v between min and max to a dot up a canvas dots high.
+func scaleValue(v, min, max float64, dots int) int {
+	return int(math.Round((v - min) / (max - min) * float64(dots-1)))
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/genuinetools/weather/forecast"
+	"github.com/mitchellh/colorstring"
+)
+
+const (
+	checkExitMatched    = 0
+	checkExitNotMatched = 1
+	checkExitError      = 2
+)
+
+const checkHelp = `Check conditions against the forecast.
+
+Each condition is in the form "<field> <op> <value> [within <n>h|<n>d]" where
+field is any field of the forecast data (e.g. temperature, precipProbability,
+windSpeed, icon) and op is one of <, <=, >, >=, ==, !=. Without "within" the
+current weather is checked, "within 3h" checks the next 3 hours and
+"within 2d" checks the daily forecast for the next 2 days.
+
+Exits 0 if the conditions matched, 1 if they did not and 2 on error.`
+
+func (cmd *checkCommand) Name() string      { return "check" }
+func (cmd *checkCommand) Args() string      { return "[OPTIONS] CONDITION [CONDITION...]" }
+func (cmd *checkCommand) ShortHelp() string { return "Check conditions against the forecast." }
+func (cmd *checkCommand) LongHelp() string  { return checkHelp }
+func (cmd *checkCommand) Hidden() bool      { return false }
+
+func (cmd *checkCommand) Register(fs *flag.FlagSet) {
+	fs.BoolVar(&cmd.any, "any", false, "exit 0 if any rather than all of the conditions match")
+	fs.BoolVar(&cmd.quiet, "quiet", false, "do not print which conditions matched")
+	fs.BoolVar(&cmd.quiet, "q", false, "do not print which conditions matched (shorthand)")
+}
+
+type checkCommand struct {
+	any   bool
+	quiet bool
+}
+
+func (cmd *checkCommand) Run(ctx context.Context, args []string) error {
+	if len(args) < 1 {
+		cmd.exit(checkExitError, fmt.Errorf("pass at least one condition to check"))
+	}
+
+	conditions := make([]forecast.Condition, 0, len(args))
+	for _, arg := range args {
+		c, err := forecast.ParseCondition(arg)
+		if err != nil {
+			cmd.exit(checkExitError, err)
+		}
+		conditions = append(conditions, c)
+	}
+
+	g, err := getLocation()
+	if err != nil {
+		cmd.exit(checkExitError, err)
+	}
+
+	fc, err := getForecast(g)
+	if err != nil {
+		cmd.exit(checkExitError, err)
+	}
+
+	matched := 0
+	for _, c := range conditions {
+		weather, ok := c.Match(fc)
+		if ok {
+			matched++
+		}
+
+		if cmd.quiet {
+			continue
+		}
+
+		when := "now"
+		switch {
+		case weather.Time == fc.Currently.Time:
+		case c.Daily:
+			when = "on " + time.Unix(weather.Time, 0).In(fc.Location()).Format("Monday")
+		default:
+			when = "at " + time.Unix(weather.Time, 0).In(fc.Location()).Format("Mon 3:04pm")
+		}
+		if ok {
+			fmt.Println(colorstring.Color(fmt.Sprintf("[green]matched[reset]      %s (%s %s)", c.Expr, c.FieldValue(weather), when)))
+		} else {
+			fmt.Println(colorstring.Color(fmt.Sprintf("[red]not matched[reset]  %s (%s %s)", c.Expr, c.FieldValue(weather), when)))
+		}
+	}
+
+	if matched == len(conditions) || (cmd.any && matched > 0) {
+		os.Exit(checkExitMatched)
+	}
+	os.Exit(checkExitNotMatched)
+	return nil
+}
+
+// exit prints the error and exits with the code, bypassing the cli package
+// which always exits 1 on error.
+func (cmd *checkCommand) exit(code int, err error) {
+	fmt.Fprintln(os.Stderr, colorstring.Color("[red]"+err.Error()))
+	os.Exit(code)
+}
@@ -0,0 +1,100 @@
+package comfort
+
+// Categories of how the weather feels, from the coldest to the hottest.
+const (
+	DangerousCold = "dangerously cold"
+	Cold          = "cold"
+	Cool          = "cool"
+	Comfortable   = "comfortable"
+	Warm          = "warm"
+	Hot           = "hot"
+	DangerousHeat = "dangerously hot"
+)
+
+// Thresholds are where the categories start and when the air is called
+// muggy or humid. The temperatures are in °C.
+type Thresholds struct {
+	// DangerousWindChill is the wind chill below which exposed skin gets
+	// frostbite quickly.
+	DangerousWindChill float64 `json:"dangerousWindChill"`
+	// Cold, Cool, Warm and Hot are the temperatures it feels like that
+	// the categories start at, below Cold is cold and below Cool is cool.
+	Cold float64 `json:"cold"`
+	Cool float64 `json:"cool"`
+	Warm float64 `json:"warm"`
+	Hot  float64 `json:"hot"`
+	// DangerousWBGT is the wet-bulb globe temperature from which heat
+	// stress is dangerous.
+	DangerousWBGT float64 `json:"dangerousWBGT"`
+
+	// MuggyDewPoint is the dew point above which the air feels muggy.
+	MuggyDewPoint float64 `json:"muggyDewPoint"`
+	// HumidHumidity is the relative humidity between 0 and 1 above which
+	// the air feels humid.
+	HumidHumidity float64 `json:"humidHumidity"`
+}
+
+// DefaultThresholds are the thresholds used unless they are configured.
+var DefaultThresholds = Thresholds{
+	DangerousWindChill: -27,
+	Cold:               5,
+	Cool:               15,
+	Warm:               24,
+	Hot:                30,
+	DangerousWBGT:      31,
+	MuggyDewPoint:      16,
+	HumidHumidity:      0.7,
+}
+
+// Metrics describe how the weather feels.
+type Metrics struct {
+	// HeatIndex and WindChill are the temperature when they do not apply.
+	HeatIndex float64 `json:"heatIndex"`
+	WindChill float64 `json:"windChill"`
+	Humidex   float64 `json:"humidex"`
+	WBGT      float64 `json:"wbgt"`
+	// FeelsLike is the heat index in the heat, the wind chill in the cold
+	// and otherwise the temperature.
+	FeelsLike float64 `json:"feelsLike"`
+	Category  string  `json:"category"`
+	Muggy     bool    `json:"muggy"`
+	Humid     bool    `json:"humid"`
+}
+
+// Compute returns the metrics for the temperature and dew point in °C, the
+// relative humidity between 0 and 1 and the wind speed in m/s.
+func Compute(temperature, dewPoint, humidity, windSpeed float64, t Thresholds) Metrics {
+	m := Metrics{
+		HeatIndex: HeatIndex(temperature, humidity),
+		WindChill: WindChill(temperature, windSpeed),
+		Humidex:   Humidex(temperature, dewPoint),
+		WBGT:      WBGT(temperature, humidity),
+		FeelsLike: temperature,
+		Muggy:     dewPoint > t.MuggyDewPoint,
+		Humid:     humidity > t.HumidHumidity,
+	}
+	switch {
+	case temperature >= HeatIndexMin:
+		m.FeelsLike = m.HeatIndex
+	case temperature <= WindChillMax:
+		m.FeelsLike = m.WindChill
+	}
+
+	switch {
+	case m.WBGT >= t.DangerousWBGT:
+		m.Category = DangerousHeat
+	case m.FeelsLike >= t.Hot:
+		m.Category = Hot
+	case m.FeelsLike >= t.Warm:
+		m.Category = Warm
+	case m.FeelsLike >= t.Cool:
+		m.Category = Comfortable
+	case m.FeelsLike >= t.Cold:
+		m.Category = Cool
+	case m.WindChill < t.DangerousWindChill:
+		m.Category = DangerousCold
+	default:
+		m.Category = Cold
+	}
+	return m
+}
@@ -0,0 +1,147 @@
+package comfort
+
+import (
+	"math"
+	"testing"
+)
+
+func fahrenheit(c float64) float64 { return c*9/5 + 32 }
+func celsius(f float64) float64    { return (f - 32) * 5 / 9 }
+
+func TestHeatIndex(t *testing.T) {
+	// from the National Weather Service's heat index chart, in °F
+	testCases := []struct {
+		temperature, humidity, expected float64
+	}{
+		{86, 90, 105},
+		{90, 50, 95},
+		{90, 70, 106},
+		{96, 65, 121},
+		{100, 40, 109},
+		{100, 60, 129},
+		{104, 55, 137},
+		{110, 40, 136},
+		// below HeatIndexMin it is the temperature
+		{80, 100, 80},
+		{50, 50, 50},
+	}
+
+	for _, tc := range testCases {
+		got := fahrenheit(HeatIndex(celsius(tc.temperature), tc.humidity/100))
+		if math.Round(got) != tc.expected {
+			t.Errorf("%g°F at %g%%: expected a heat index of %g°F, got %.2f°F", tc.temperature, tc.humidity, tc.expected, got)
+		}
+	}
+}
+
+func TestWindChill(t *testing.T) {
+	// from the National Weather Service's wind chill chart, in °F and mph
+	const mph = 0.44704
+	testCases := []struct {
+		temperature, windSpeed, expected float64
+	}{
+		{40, 10, 34},
+		{30, 5, 25},
+		{20, 10, 9},
+		{5, 30, -19},
+		{0, 15, -19},
+		{-10, 20, -35},
+		{-20, 40, -57},
+		// above WindChillMax and in a light wind it is the temperature
+		{60, 20, 60},
+		{20, 2, 20},
+	}
+
+	for _, tc := range testCases {
+		got := fahrenheit(WindChill(celsius(tc.temperature), tc.windSpeed*mph))
+		if math.Round(got) != tc.expected {
+			t.Errorf("%g°F in %g mph: expected a wind chill of %g°F, got %.2f°F", tc.temperature, tc.windSpeed, tc.expected, got)
+		}
+	}
+}
+
+func TestHumidex(t *testing.T) {
+	testCases := []struct {
+		temperature, dewPoint, expected float64
+	}{
+		// Environment Canada's example
+		{30, 15, 34},
+		{30, 10, 31},
+		{25, 20, 33},
+	}
+
+	for _, tc := range testCases {
+		if got := Humidex(tc.temperature, tc.dewPoint); math.Round(got) != tc.expected {
+			t.Errorf("%g°C with a dew point of %g°C: expected a humidex of %g, got %.2f", tc.temperature, tc.dewPoint, tc.expected, got)
+		}
+	}
+}
+
+func TestWBGT(t *testing.T) {
+	testCases := []struct {
+		temperature, humidity, expected float64
+	}{
+		{25, 0.5, 24.3},
+		{35, 0.6, 37},
+		{20, 0, 15.3},
+	}
+
+	for _, tc := range testCases {
+		if got := WBGT(tc.temperature, tc.humidity); math.Abs(got-tc.expected) > 0.05 {
+			t.Errorf("%g°C at %g: expected a WBGT of %g°C, got %.2f°C", tc.temperature, tc.humidity, tc.expected, got)
+		}
+	}
+}
+
+func TestCompute(t *testing.T) {
+	// thresholds a degree apart from the defaults, to tell them apart
+	custom := Thresholds{
+		DangerousWindChill: -20,
+		Cold:               0,
+		Cool:               10,
+		Warm:               20,
+		Hot:                25,
+		DangerousWBGT:      28,
+		MuggyDewPoint:      12,
+		HumidHumidity:      0.5,
+	}
+
+	testCases := []struct {
+		name                                       string
+		temperature, dewPoint, humidity, windSpeed float64
+		thresholds                                 Thresholds
+		category                                   string
+		muggy, humid                               bool
+	}{
+		{name: "mild", temperature: 20, dewPoint: 10, humidity: 0.5, thresholds: DefaultThresholds, category: Comfortable},
+		{name: "cool from", temperature: 15, dewPoint: 5, humidity: 0.5, thresholds: DefaultThresholds, category: Comfortable},
+		{name: "cool below", temperature: 14.9, dewPoint: 5, humidity: 0.5, thresholds: DefaultThresholds, category: Cool},
+		{name: "warm from", temperature: 24, dewPoint: 5, humidity: 0.3, thresholds: DefaultThresholds, category: Warm},
+		{name: "cold below", temperature: 4.9, dewPoint: 0, humidity: 0.5, thresholds: DefaultThresholds, category: Cold},
+		// the wind chill makes it feel colder
+		{name: "cold in the wind", temperature: 8, dewPoint: 0, humidity: 0.5, windSpeed: 10, thresholds: DefaultThresholds, category: Cold},
+		{name: "dangerous wind chill", temperature: -20, dewPoint: -25, humidity: 0.6, windSpeed: 10, thresholds: DefaultThresholds, category: DangerousCold},
+		// the heat index makes it feel hotter
+		{name: "hot in the humidity", temperature: 29, dewPoint: 20.5, humidity: 0.6, thresholds: DefaultThresholds, category: Hot, muggy: true},
+		{name: "dangerous heat", temperature: 34, dewPoint: 26, humidity: 0.65, thresholds: DefaultThresholds, category: DangerousHeat, muggy: true},
+		{name: "muggy from", temperature: 20, dewPoint: 16, humidity: 0.7, thresholds: DefaultThresholds, category: Comfortable},
+		{name: "muggy above", temperature: 20, dewPoint: 16.1, humidity: 0.71, thresholds: DefaultThresholds, category: Comfortable, muggy: true, humid: true},
+
+		{name: "custom cold from", temperature: 0, dewPoint: -5, humidity: 0.4, thresholds: custom, category: Cool},
+		{name: "custom cold below", temperature: -0.1, dewPoint: -5, humidity: 0.4, thresholds: custom, category: Cold},
+		{name: "custom comfortable from", temperature: 10, dewPoint: 0, humidity: 0.4, thresholds: custom, category: Comfortable},
+		{name: "custom warm from", temperature: 20, dewPoint: 0, humidity: 0.3, thresholds: custom, category: Warm},
+		{name: "custom hot from", temperature: 25, dewPoint: 0, humidity: 0.2, thresholds: custom, category: Hot},
+		{name: "custom dangerous heat", temperature: 26, dewPoint: 22, humidity: 0.8, thresholds: custom, category: DangerousHeat, muggy: true, humid: true},
+		{name: "custom dangerous wind chill", temperature: -12, dewPoint: -20, humidity: 0.4, windSpeed: 15, thresholds: custom, category: DangerousCold},
+		{name: "custom muggy", temperature: 15, dewPoint: 12.1, humidity: 0.5, thresholds: custom, category: Comfortable, muggy: true},
+	}
+
+	for _, tc := range testCases {
+		m := Compute(tc.temperature, tc.dewPoint, tc.humidity, tc.windSpeed, tc.thresholds)
+		if m.Category != tc.category || m.Muggy != tc.muggy || m.Humid != tc.humid {
+			t.Errorf("%s: expected %s, muggy %t and humid %t, got %s, muggy %t and humid %t (%+v)",
+				tc.name, tc.category, tc.muggy, tc.humid, m.Category, m.Muggy, m.Humid, m)
+		}
+	}
+}
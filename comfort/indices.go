@@ -0,0 +1,75 @@
+// Package comfort derives how the weather feels from the temperature,
+// humidity and wind: the heat index, wind chill, humidex, an approximate
+// wet-bulb globe temperature and a plain language category.
+//
+// Temperatures are in °C and wind speeds in m/s, the canonical units of the
+// units package.
+package comfort
+
+import (
+	"math"
+)
+
+// HeatIndexMin is the temperature in °C the heat index is used from, below
+// it the heat index is the temperature.
+const HeatIndexMin = 26.7
+
+// WindChillMax is the temperature in °C the wind chill is used up to, above
+// it the wind chill is the temperature.
+const WindChillMax = 10.0
+
+// windChillMinSpeed is the wind speed in m/s the wind chill is used from.
+const windChillMinSpeed = 4.8 / 3.6
+
+// HeatIndex returns the heat index in °C for the temperature and the
+// relative humidity between 0 and 1, using the National Weather Service's
+// regression.
+func HeatIndex(temperature, humidity float64) float64 {
+	if temperature < HeatIndexMin {
+		return temperature
+	}
+
+	t := temperature*9/5 + 32
+	rh := humidity * 100
+	hi := -42.379 + 2.04901523*t + 10.14333127*rh -
+		0.22475541*t*rh - 0.00683783*t*t - 0.05481717*rh*rh +
+		0.00122874*t*t*rh + 0.00085282*t*rh*rh - 0.00000199*t*t*rh*rh
+
+	// the regression is adjusted at the extremes of the humidity
+	switch {
+	case rh < 13 && t >= 80 && t <= 112:
+		hi -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(t-95))/17)
+	case rh > 85 && t >= 80 && t <= 87:
+		hi += (rh - 85) / 10 * (87 - t) / 5
+	}
+
+	return (hi - 32) * 5 / 9
+}
+
+// WindChill returns the wind chill in °C for the temperature and the wind
+// speed in m/s, using the formula of the National Weather Service and
+// Environment Canada.
+func WindChill(temperature, windSpeed float64) float64 {
+	if temperature > WindChillMax || windSpeed <= windChillMinSpeed {
+		return temperature
+	}
+
+	v := math.Pow(windSpeed*3.6, 0.16)
+	return 13.12 + 0.6215*temperature - 11.37*v + 0.3965*temperature*v
+}
+
+// Humidex returns the Canadian humidex for the temperature and the dew
+// point, a number comparable to a temperature in °C.
+func Humidex(temperature, dewPoint float64) float64 {
+	e := 6.11 * math.Exp(5417.7530*(1/273.16-1/(273.15+dewPoint)))
+	return temperature + 0.5555*(e-10)
+}
+
+// WBGT returns an approximate wet-bulb globe temperature in °C for the
+// temperature and the relative humidity between 0 and 1, using the Bureau
+// of Meteorology's formula. It assumes shade and a light wind, so it
+// underestimates the heat stress in full sun.
+func WBGT(temperature, humidity float64) float64 {
+	e := humidity * 6.105 * math.Exp(17.27*temperature/(237.7+temperature))
+	return 0.567*temperature + 0.393*e + 3.94
+}
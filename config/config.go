@@ -0,0 +1,138 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/genuinetools/weather/comfort"
+	"github.com/genuinetools/weather/forecast"
+	"github.com/genuinetools/weather/units"
+)
+
+// Config is the configuration file for weather, it comes like:
+// {
+//     "locations": {
+//         "home": "10028",
+//         "office": "Manhattan Beach, CA"
+//     },
+//     "units": {
+//         "name": "si",
+//         "speed": "mph",
+//         "pressure": "inHg"
+//     },
+//     "comfort": {
+//         "muggyDewPoint": 18,
+//         "hot": 32
+//     },
+//     "advice": {
+//         "window": "08:00-09:00",
+//         "rules": "~/.config/weather/advice.json"
+//     },
+//     "windows": {
+//         "commute": "weekdays 08:00-09:00 and 17:30-18:30"
+//     },
+//     "changes": {
+//         "temperature": 2,
+//         "precipProbability": 0.2
+//     },
+//     "webhooks": [
+//         {
+//             "url": "https://hooks.slack.com/services/...",
+//             "format": "slack"
+//         }
+//     ]
+// }
+type Config struct {
+	Locations map[string]string  `json:"locations"`
+	Units     Units              `json:"units"`
+	Comfort   comfort.Thresholds `json:"comfort"`
+	Advice    Advice             `json:"advice"`
+	// Windows are recurring windows of time by name, see
+	// forecast.ParseSchedule.
+	Windows map[string]string `json:"windows"`
+	// Changes are how big a revision to the forecast has to be to be
+	// reported as a change since the last run.
+	Changes  forecast.RevisionThresholds `json:"changes"`
+	Webhooks []Webhook                   `json:"webhooks"`
+}
+
+// Units are the units to show the weather in. Name is the system of units
+// (auto, us, si, ca or uk2) used unless the flags pass one, and the unit of
+// each quantity set overrides the system's.
+type Units struct {
+	Name string `json:"name"`
+	units.System
+}
+
+// Advice configures what to wear and bring. Window is the time of day to
+// advise for, e.g. "08:00-09:00" for the commute, rather than the next 12
+// hours, and Rules is the path to a file of rules to add to the defaults.
+type Advice struct {
+	Window string `json:"window"`
+	Rules  string `json:"rules"`
+}
+
+// Webhook describes an endpoint notifications are posted to.
+type Webhook struct {
+	URL string `json:"url"`
+	// Format is the payload format: slack, matrix or json.
+	Format string `json:"format"`
+	// Headers are sent with every request, e.g. for authorization.
+	Headers map[string]string `json:"headers"`
+}
+
+// Path returns the default path of the configuration file.
+func Path() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "weather", "config.json")
+}
+
+// StateDir returns the directory used to store state between runs.
+func StateDir() string {
+	dir := os.Getenv("XDG_CACHE_HOME")
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".cache")
+	}
+	return filepath.Join(dir, "weather")
+}
+
+// Load reads the configuration file at path. A missing file is not an
+// error and returns an empty configuration. The comfort and revision
+// thresholds not set in the file are the defaults.
+func Load(path string) (config Config, err error) {
+	config.Comfort = comfort.DefaultThresholds
+	config.Changes = forecast.DefaultRevisionThresholds
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(&config); err != nil {
+		return config, fmt.Errorf("decoding config file %s failed: %v", path, err)
+	}
+
+	return config, nil
+}
+
+// LocationNames returns the names of the saved locations in sorted order.
+func (c Config) LocationNames() []string {
+	names := make([]string, 0, len(c.Locations))
+	for name := range c.Locations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/genuinetools/weather/config"
+	"github.com/genuinetools/weather/forecast"
+	"github.com/genuinetools/weather/geocode"
+)
+
+const diffHelp = `Show what changed in the forecast since the last run.
+
+The last forecast fetched for each location, by this command or the weather
+command, is kept and compared to the one fetched now. How big a change to the
+hourly and daily forecast has to be to be shown is set with "changes" in the
+config file.`
+
+func (cmd *diffCommand) Name() string      { return "diff" }
+func (cmd *diffCommand) Args() string      { return "[OPTIONS]" }
+func (cmd *diffCommand) ShortHelp() string { return "Show what changed in the forecast." }
+func (cmd *diffCommand) LongHelp() string  { return diffHelp }
+func (cmd *diffCommand) Hidden() bool      { return false }
+
+func (cmd *diffCommand) Register(fs *flag.FlagSet) {}
+
+type diffCommand struct{}
+
+func (cmd *diffCommand) Run(ctx context.Context, args []string) error {
+	g, err := getLocation()
+	if err != nil {
+		return err
+	}
+
+	fc, err := getForecast(g)
+	if err != nil {
+		return err
+	}
+
+	previous, ok, err := loadLastForecast(g)
+	if err != nil {
+		return err
+	}
+	if err := saveLastForecast(g, fc); err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Println("There is no earlier forecast for this location yet, run this again later to see what changed.")
+		return nil
+	}
+
+	revisions, err := getRevisions(previous, fc)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		for _, revision := range revisions {
+			if err := enc.Encode(revision); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return renderRevisions(os.Stdout, previous, revisions)
+}
+
+// lastForecastPath returns the file the last forecast fetched for the
+// location is kept in.
+func lastForecastPath(g geocode.Geocode) string {
+	return filepath.Join(config.StateDir(), "last", fmt.Sprintf("%.3f,%.3f.json", g.Latitude, g.Longitude))
+}
+
+// loadLastForecast returns the last forecast fetched for the location, or
+// false if there is none.
+func loadLastForecast(g geocode.Geocode) (forecast.Forecast, bool, error) {
+	var fc forecast.Forecast
+	b, err := ioutil.ReadFile(lastForecastPath(g))
+	if os.IsNotExist(err) {
+		return fc, false, nil
+	}
+	if err != nil {
+		return fc, false, err
+	}
+	if err := json.Unmarshal(b, &fc); err != nil {
+		return fc, false, fmt.Errorf("decoding the last forecast %s failed: %v", lastForecastPath(g), err)
+	}
+	return fc, true, nil
+}
+
+// saveLastForecast keeps the forecast as the last fetched for the location.
+// A forecast without the hourly or daily blocks, e.g. with -no-forecast, is
+// not kept, so the next run still has them to compare with.
+func saveLastForecast(g geocode.Geocode, fc forecast.Forecast) error {
+	if len(fc.Hourly.Data) == 0 || len(fc.Daily.Data) == 0 {
+		return nil
+	}
+
+	path := lastForecastPath(g)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(fc)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so we never leave a partial forecast
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// getRevisions returns the significant revisions of the forecast since the
+// previous one, by the thresholds set in the config.
+func getRevisions(previous, fc forecast.Forecast) ([]forecast.Revision, error) {
+	conf, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	opts, err := getOptions()
+	if err != nil {
+		return nil, err
+	}
+	return forecast.Revisions(previous, fc, conf.Changes, opts), nil
+}
+
+// renderRevisions writes the revisions of the forecast since the previous
+// one.
+func renderRevisions(w io.Writer, previous forecast.Forecast, revisions []forecast.Revision) error {
+	opts, err := getOptions()
+	if err != nil {
+		return err
+	}
+	return forecast.PrintRevisions(w, previous, revisions, opts)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/genuinetools/weather/forecast"
+	"github.com/genuinetools/weather/geocode"
+)
+
+func TestSaveLastForecast(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	g := geocode.Geocode{Latitude: 40.7, Longitude: -74}
+
+	full := forecast.Forecast{
+		Currently: forecast.Weather{Time: 1709280000, Temperature: 10},
+		Hourly:    forecast.TimeDelimited{Data: []forecast.Weather{{Time: 1709280000, Temperature: 10}}},
+		Daily:     forecast.TimeDelimited{Data: []forecast.Weather{{Time: 1709269200, TemperatureMax: 14}}},
+	}
+	if err := saveLastForecast(g, full); err != nil {
+		t.Fatal(err)
+	}
+
+	// a forecast without the hourly block, from -no-forecast, keeps the
+	// last full one
+	current := full
+	current.Currently.Time += 60 * 60
+	current.Hourly = forecast.TimeDelimited{}
+	if err := saveLastForecast(g, current); err != nil {
+		t.Fatal(err)
+	}
+
+	fc, ok, err := loadLastForecast(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("expected a forecast to be kept")
+	}
+	if fc.Currently.Time != full.Currently.Time || len(fc.Hourly.Data) != 1 || len(fc.Daily.Data) != 1 {
+		t.Errorf("expected the full forecast to be kept, got %+v", fc)
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/genuinetools/weather/config"
+	"github.com/genuinetools/weather/forecast"
+	"github.com/genuinetools/weather/geocode"
+	"github.com/genuinetools/weather/units"
+	"github.com/sirupsen/logrus"
+)
+
+const exporterHelp = `Run a Prometheus exporter for the weather of the saved locations.
+
+The forecast for every location saved in the config file is refreshed on
+the interval, not on each scrape, so scrapes never use up the API quota.
+The hourly forecast is exported for the next -hours hours, 24 by default.`
+
+func (cmd *exporterCommand) Name() string      { return "exporter" }
+func (cmd *exporterCommand) Args() string      { return "[OPTIONS]" }
+func (cmd *exporterCommand) ShortHelp() string { return "Run a Prometheus exporter for the weather." }
+func (cmd *exporterCommand) LongHelp() string  { return exporterHelp }
+func (cmd *exporterCommand) Hidden() bool      { return false }
+
+func (cmd *exporterCommand) Register(fs *flag.FlagSet) {
+	fs.StringVar(&cmd.port, "port", "9776", "port for the exporter to run on")
+	fs.DurationVar(&cmd.interval, "interval", 10*time.Minute, "how often to refresh the forecast")
+}
+
+type exporterCommand struct {
+	port     string
+	interval time.Duration
+	// hours of hourly forecast to export, from the global -hours flag
+	hours int
+
+	geocodes map[string]geocode.Geocode
+
+	mu        sync.RWMutex
+	forecasts map[string]forecast.Forecast
+	updated   map[string]time.Time
+}
+
+// metric is a gauge that is exported for every location.
+type metric struct {
+	name  string
+	help  string
+	value func(forecast.Weather, units.System) float64
+}
+
+// currentMetrics are exported for the current weather and, with an
+// hours_ahead label, for the hourly forecast.
+var currentMetrics = []metric{
+	{"temperature", "Temperature in the units of the units label.", func(w forecast.Weather, _ units.System) float64 { return w.Temperature }},
+	{"apparent_temperature", "Apparent (feels like) temperature in the units of the units label.", func(w forecast.Weather, _ units.System) float64 { return w.ApparentTemperature }},
+	{"humidity", "Relative humidity between 0 and 1.", func(w forecast.Weather, _ units.System) float64 { return w.Humidity }},
+	{"dew_point", "Dew point in the units of the units label.", func(w forecast.Weather, _ units.System) float64 { return w.DewPoint }},
+	{"wind_speed", "Wind speed in the units of the units label.", func(w forecast.Weather, _ units.System) float64 { return w.WindSpeed }},
+	{"wind_bearing_degrees", "Direction the wind is coming from in degrees.", func(w forecast.Weather, _ units.System) float64 { return w.WindBearing }},
+	{"precip_probability", "Probability of precipitation between 0 and 1.", func(w forecast.Weather, _ units.System) float64 { return w.PrecipProbability }},
+	{"precip_intensity", "Precipitation intensity in the units of the units label.", func(w forecast.Weather, _ units.System) float64 { return w.PrecipIntensity }},
+	{"cloud_cover", "Cloud cover between 0 and 1.", func(w forecast.Weather, _ units.System) float64 { return w.CloudCover }},
+	{"pressure_hpa", "Sea level air pressure in hectopascals.", func(w forecast.Weather, system units.System) float64 {
+		// the pressure is always exported in hectopascals
+		return units.Round(units.PressureIn(w.Pressure, system.Pressure).In(units.Hectopascals))
+	}},
+}
+
+func (cmd *exporterCommand) Run(ctx context.Context, args []string) error {
+	if cmd.interval < minWatchInterval {
+		return fmt.Errorf("interval must be at least %s", minWatchInterval)
+	}
+
+	cmd.hours = hours
+	if cmd.hours < 1 {
+		cmd.hours = 24
+	}
+
+	conf, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if len(conf.Locations) < 1 {
+		return fmt.Errorf("no locations saved in the config file %s", configPath)
+	}
+
+	ctx, cancel := withSignals(ctx)
+	defer cancel()
+
+	cmd.geocodes = map[string]geocode.Geocode{}
+	cmd.forecasts = map[string]forecast.Forecast{}
+	cmd.updated = map[string]time.Time{}
+
+	// Refresh the forecasts in the background.
+	go func() {
+		ticker := time.NewTicker(cmd.interval)
+		defer ticker.Stop()
+		for {
+			cmd.refresh(conf)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", cmd.metricsHandler)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><title>Weather Exporter</title></head><body><h1>Weather Exporter</h1><p><a href="/metrics">Metrics</a></p></body></html>`)
+	})
+
+	server := &http.Server{
+		Addr:    ":" + cmd.port,
+		Handler: mux,
+	}
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+
+	logrus.Infof("Starting exporter on port %q", cmd.port)
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// refresh gets the forecast for all the saved locations.
+func (cmd *exporterCommand) refresh(conf config.Config) {
+	for _, name := range conf.LocationNames() {
+		g, err := locateSaved(conf, cmd.geocodes, name)
+		if err != nil {
+			logrus.Warnf("locating %s failed: %v", name, err)
+			continue
+		}
+
+		fc, err := getForecast(g, "minutely", "daily")
+		if err != nil {
+			logrus.Warnf("getting the forecast for %s failed: %v", name, err)
+			continue
+		}
+
+		cmd.mu.Lock()
+		cmd.forecasts[name] = fc
+		cmd.updated[name] = time.Now()
+		cmd.mu.Unlock()
+	}
+}
+
+// metricsHandler writes the metrics in the Prometheus text format.
+func (cmd *exporterCommand) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	cmd.mu.RLock()
+	defer cmd.mu.RUnlock()
+
+	names := make([]string, 0, len(cmd.forecasts))
+	for name := range cmd.forecasts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	for _, m := range currentMetrics {
+		writeMetricHeader(&b, "weather_"+m.name, m.help)
+		for _, name := range names {
+			fc := cmd.forecasts[name]
+			writeSample(&b, "weather_"+m.name, m.value(fc.Currently, fc.System()), "location", name, "units", fc.Flags.Units)
+		}
+	}
+
+	writeMetricHeader(&b, "weather_alerts_active", "Number of weather alerts active for the location.")
+	for _, name := range names {
+		writeSample(&b, "weather_alerts_active", float64(len(cmd.forecasts[name].Alerts)), "location", name)
+	}
+
+	writeMetricHeader(&b, "weather_last_update_timestamp_seconds", "Unix time the forecast was last refreshed.")
+	for _, name := range names {
+		writeSample(&b, "weather_last_update_timestamp_seconds", float64(cmd.updated[name].Unix()), "location", name)
+	}
+
+	for _, m := range currentMetrics {
+		writeMetricHeader(&b, "weather_forecast_"+m.name, "Hourly forecast: "+m.help)
+		for _, name := range names {
+			fc := cmd.forecasts[name]
+			for _, hourly := range fc.Hourly.Data {
+				ahead := hoursAhead(fc.Currently.Time, hourly.Time)
+				if ahead < 0 {
+					continue
+				}
+				if ahead >= cmd.hours {
+					break
+				}
+				writeSample(&b, "weather_forecast_"+m.name, m.value(hourly, fc.System()), "location", name, "units", fc.Flags.Units, "hours_ahead", strconv.Itoa(ahead))
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := w.Write(b.Bytes()); err != nil {
+		logrus.Warnf("writing metrics failed: %v", err)
+	}
+}
+
+// hoursAhead returns how many hours after now the hour starting at t is,
+// zero for the hour now is in.
+func hoursAhead(now, t int64) int {
+	return int(math.Ceil(float64(t-now) / (60 * 60)))
+}
+
+func writeMetricHeader(b *bytes.Buffer, name, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+}
+
+// writeSample writes a sample with the label name and value pairs.
+func writeSample(b *bytes.Buffer, name string, value float64, labels ...string) {
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(b, "%s=\"%s\"", labels[i], labelEscaper.Replace(labels[i+1]))
+		}
+		b.WriteByte('}')
+	}
+	fmt.Fprintf(b, " %s\n", strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/genuinetools/weather/forecast"
+	"github.com/genuinetools/weather/units"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	// now is 25 minutes into the hour the hourly forecast starts at
+	const hour = 1709280000
+	now := int64(hour + 25*60)
+
+	fc := forecast.Forecast{
+		Flags: forecast.Flags{Units: "us"},
+		Currently: forecast.Weather{
+			Time:        now,
+			Temperature: 50,
+			Pressure:    1013.2,
+		},
+	}
+	for i := -1; i < 5; i++ {
+		fc.Hourly.Data = append(fc.Hourly.Data, forecast.Weather{
+			Time:        hour + int64(i)*60*60,
+			Temperature: 50 + float64(i),
+			Pressure:    1013.2,
+		})
+	}
+
+	// a location with the pressure in inches of mercury from the config
+	system := units.Systems["us"].Override(units.System{Pressure: units.InchesOfMercury})
+	cabin := forecast.Forecast{
+		Flags:     forecast.Flags{Units: system.Name(), System: &system},
+		Currently: forecast.Weather{Time: now, Temperature: 40, Pressure: 29.92},
+	}
+
+	cmd := &exporterCommand{
+		hours:     3,
+		forecasts: map[string]forecast.Forecast{"home": fc, "cabin": cabin},
+		updated:   map[string]time.Time{"home": time.Unix(now, 0), "cabin": time.Unix(now, 0)},
+	}
+	w := httptest.NewRecorder()
+	cmd.metricsHandler(w, httptest.NewRequest("GET", "/metrics", nil))
+	out := w.Body.String()
+
+	for _, s := range []string{
+		`weather_temperature{location="home",units="us"} 50` + "\n",
+		`weather_pressure_hpa{location="home",units="us"} 1013.2` + "\n",
+		`weather_pressure_hpa{location="cabin",units="custom"} 1013.21` + "\n",
+		`weather_forecast_temperature{location="home",units="us",hours_ahead="0"} 50` + "\n",
+		`weather_forecast_temperature{location="home",units="us",hours_ahead="1"} 51` + "\n",
+		`weather_forecast_temperature{location="home",units="us",hours_ahead="2"} 52` + "\n",
+		`weather_forecast_pressure_hpa{location="home",units="us",hours_ahead="0"} 1013.2` + "\n",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected the metrics to contain %q, got:\n%s", s, out)
+		}
+	}
+	for _, s := range []string{`hours_ahead="-1"`, `hours_ahead="3"`, `location="home",units="custom"`} {
+		if strings.Contains(out, s) {
+			t.Errorf("expected the metrics not to contain %q, got:\n%s", s, out)
+		}
+	}
+}
+
+func TestHoursAhead(t *testing.T) {
+	const hour = 1709280000
+	testCases := []struct {
+		now, t   int64
+		expected int
+	}{
+		{now: hour, t: hour, expected: 0},
+		{now: hour + 25*60, t: hour, expected: 0},
+		{now: hour + 25*60, t: hour + 60*60, expected: 1},
+		{now: hour + 59*60, t: hour + 60*60, expected: 1},
+		{now: hour, t: hour + 48*60*60, expected: 48},
+		{now: hour + 25*60, t: hour - 60*60, expected: -1},
+	}
+
+	for _, tc := range testCases {
+		if got := hoursAhead(tc.now, tc.t); got != tc.expected {
+			t.Errorf("hoursAhead(%d, %d): expected %d, got %d", tc.now, tc.t, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package forecast
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/genuinetools/weather/units"
+	"github.com/mitchellh/colorstring"
+)
+
+// Rule is a piece of advice given if all of its conditions hold for an hour
+// of the window. The conditions compare the forecast in "si" units, e.g.
+// "temperature < 5" is below 5°C.
+type Rule struct {
+	Advice string   `json:"advice"`
+	When   []string `json:"when"`
+	// Group names rules that exclude each other, only the first that
+	// applies in a group is given.
+	Group string `json:"group,omitempty"`
+
+	conditions []Condition
+}
+
+// DefaultRules are the rules in advice.json.
+var DefaultRules = func() []Rule {
+	rules, err := ParseRules([]byte(defaultRulesJSON))
+	if err != nil {
+		panic(err)
+	}
+	return rules
+}()
+
+// ParseRules parses a JSON array of rules and their conditions.
+func ParseRules(b []byte) ([]Rule, error) {
+	var rules []Rule
+	if err := json.Unmarshal(b, &rules); err != nil {
+		return nil, fmt.Errorf("decoding advice rules failed: %v", err)
+	}
+
+	for i, rule := range rules {
+		if rule.Advice == "" || len(rule.When) == 0 {
+			return nil, fmt.Errorf("advice rule %d needs both advice and conditions", i+1)
+		}
+		for _, expr := range rule.When {
+			c, err := ParseCondition(expr)
+			if err != nil {
+				return nil, fmt.Errorf("advice rule %q: %v", rule.Advice, err)
+			}
+			if c.Within != 0 {
+				return nil, fmt.Errorf("advice rule %q: %q cannot look ahead, the rules apply to the window", rule.Advice, expr)
+			}
+			rules[i].conditions = append(rules[i].conditions, c)
+		}
+	}
+	return rules, nil
+}
+
+// applies reports whether all of the rule's conditions hold for the
+// weather.
+func (r Rule) applies(weather Weather) bool {
+	for _, c := range r.conditions {
+		if !c.matches(weather) {
+			return false
+		}
+	}
+	return true
+}
+
+// Advice is what to wear and bring for a window of the forecast.
+type Advice struct {
+	Start int64    `json:"start"`
+	End   int64    `json:"end"`
+	Items []string `json:"items"`
+}
+
+// Advise returns the advice of the rules for the next occurrence of the
+// window. The rules are DefaultRules if none are passed.
+func Advise(forecast Forecast, rules []Rule, window Window) Advice {
+	if len(rules) == 0 {
+		rules = DefaultRules
+	}
+
+	start, end := window.Next(time.Unix(forecast.Currently.Time, 0), forecast.Location())
+	advice := Advice{Start: start.Unix(), End: end.Unix(), Items: []string{}}
+
+	data := forecast.Convert(units.Systems[units.Canonical]).hours(start, end)
+	given := map[string]bool{}
+	for _, rule := range rules {
+		if given[rule.Advice] || (rule.Group != "" && given["group:"+rule.Group]) {
+			continue
+		}
+		for _, weather := range data {
+			if rule.applies(weather) {
+				advice.Items = append(advice.Items, rule.Advice)
+				given[rule.Advice] = true
+				if rule.Group != "" {
+					given["group:"+rule.Group] = true
+				}
+				break
+			}
+		}
+	}
+	return advice
+}
+
+// WithAdvice returns the forecast with the advice for the window, for the
+// JSON and template outputs.
+func (f Forecast) WithAdvice(rules []Rule, window Window) Forecast {
+	advice := Advise(f, rules, window)
+	f.Advice = &advice
+	return f
+}
+
+// printAdvice prints what to wear and bring during the window.
+func printAdvice(w io.Writer, forecast Forecast, opts Options, tf timeFormat) {
+	advice := Advise(forecast, opts.Rules, opts.Window)
+	if len(advice.Items) == 0 {
+		return
+	}
+
+	when := "for the next 12 hours"
+	if opts.Window != (Window{}) {
+		when = fmt.Sprintf("from %s to %s", tf.format(advice.Start, tf.clock()), tf.time(advice.End))
+	}
+	fmt.Fprintf(w, "What to wear and bring %s:\n", when)
+	for _, item := range advice.Items {
+		fmt.Fprintf(w, "  • %s\n", colorstring.Color("[bold]"+item))
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,176 @@
+package forecast
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRules(t *testing.T) {
+	testCases := []struct {
+		json string
+		err  string
+	}{
+		{json: `[{"advice": "Take an umbrella", "when": ["precipProbability >= 0.4", "precipType == rain"]}]`},
+		{json: `[{"advice": "Wear a coat", "group": "layers", "when": ["temperature < 5"]}]`},
+		{json: `[]`},
+		{json: `{"advice": "Take an umbrella"}`, err: "decoding advice rules failed"},
+		{json: `[{"when": ["temperature < 5"]}]`, err: "advice rule 1 needs both advice and conditions"},
+		{json: `[{"advice": "Wear a coat", "when": ["temperature < 5"]}, {"advice": "Wear a coat"}]`, err: "advice rule 2 needs both advice and conditions"},
+		{json: `[{"advice": "Wear a coat", "when": ["temperature < cold"]}]`, err: `advice rule "Wear a coat": `},
+		{json: `[{"advice": "Wear a coat", "when": ["nothing < 5"]}]`, err: `unknown field "nothing"`},
+		{json: `[{"advice": "Take an umbrella", "when": ["icon == rain within 3h"]}]`, err: "cannot look ahead"},
+	}
+
+	for _, tc := range testCases {
+		rules, err := ParseRules([]byte(tc.json))
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("ParseRules(%s): unexpected error: %v", tc.json, err)
+		case tc.err != "" && err == nil:
+			t.Errorf("ParseRules(%s): expected an error containing %q", tc.json, tc.err)
+		case tc.err != "" && !strings.Contains(err.Error(), tc.err):
+			t.Errorf("ParseRules(%s): expected an error containing %q, got %v", tc.json, tc.err, err)
+		case err == nil:
+			for _, rule := range rules {
+				if len(rule.conditions) != len(rule.When) {
+					t.Errorf("ParseRules(%s): expected %d conditions, got %d", tc.json, len(rule.When), len(rule.conditions))
+				}
+			}
+		}
+	}
+
+	if len(DefaultRules) == 0 {
+		t.Error("expected the rules of advice.json")
+	}
+}
+
+// adviceForecast returns a forecast at 10:00 UTC on March 1 2024 of the
+// hours from now, with the first as the current weather.
+func adviceForecast(units string, hours ...Weather) Forecast {
+	now := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	fc := Forecast{Timezone: "UTC", Flags: Flags{Units: units}}
+	for i, weather := range hours {
+		weather.Time = now.Add(time.Duration(i) * time.Hour).Unix()
+		fc.Hourly.Data = append(fc.Hourly.Data, weather)
+	}
+	fc.Currently = fc.Hourly.Data[0]
+	return fc
+}
+
+func TestAdvise(t *testing.T) {
+	mild := Weather{Temperature: 18, ApparentTemperature: 18, DewPoint: 8, Humidity: 0.5, Visibility: 10}
+	with := func(weather Weather, change func(*Weather)) Weather {
+		change(&weather)
+		return weather
+	}
+	rain := func(w *Weather) { w.PrecipProbability, w.PrecipType = 0.6, "rain" }
+
+	custom, err := ParseRules([]byte(`[
+		{"advice": "Go for a run", "when": ["temperature >= 10", "temperature <= 20", "precipProbability < 0.2"]},
+		{"advice": "Stay in", "when": ["precipProbability >= 0.2"]}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		forecast Forecast
+		rules    []Rule
+		window   Window
+		expected []string
+	}{
+		{
+			name:     "nothing to advise",
+			forecast: adviceForecast("si", mild, mild),
+			expected: []string{},
+		},
+		{
+			// only the first rule of the layers group is given
+			name: "cold and raining",
+			forecast: adviceForecast("si", with(mild, func(w *Weather) {
+				w.Temperature, w.ApparentTemperature = 6, 4
+				rain(w)
+			})),
+			expected: []string{"Wear a warm jacket", "Take an umbrella"},
+		},
+		{
+			name: "too windy for an umbrella",
+			forecast: adviceForecast("si", mild, with(mild, func(w *Weather) {
+				rain(w)
+				w.WindSpeed = 13
+			})),
+			expected: []string{"Wear a rain jacket, it's too windy for an umbrella", "Hold on to your hat, it will be windy"},
+		},
+		{
+			// the conditions hold in different hours of the window
+			name:     "a sweater in the morning and sunscreen at noon",
+			forecast: adviceForecast("si", with(mild, func(w *Weather) { w.ApparentTemperature = 14.9 }), mild, with(mild, func(w *Weather) { w.UVIndex = 3 })),
+			expected: []string{"Bring a light jacket or a sweater", "Wear sunscreen"},
+		},
+		{
+			name:     "on the thresholds",
+			forecast: adviceForecast("si", with(mild, func(w *Weather) { w.ApparentTemperature, w.UVIndex, w.WindSpeed = 15, 8, 10 })),
+			expected: []string{"Wear sunscreen and a hat, and stay in the shade around midday", "Hold on to your hat, it will be windy"},
+		},
+		{
+			// two rules give the same advice, which is given once
+			name: "icy roads",
+			forecast: adviceForecast("si", with(mild, func(w *Weather) {
+				w.Temperature, w.ApparentTemperature, w.DewPoint, w.Humidity = -1, -4, -1, 0.95
+				w.PrecipProbability, w.PrecipType = 0.5, "snow"
+			})),
+			expected: []string{"Wear a winter coat, hat and gloves", "Wear waterproof boots", "Roads may be icy"},
+		},
+		{
+			// the rules compare si units, 40°F is 4.4°C
+			name:     "us units",
+			forecast: adviceForecast("us", with(mild, func(w *Weather) { w.Temperature, w.ApparentTemperature, w.Visibility = 40, 40, 0.5 })),
+			expected: []string{"Wear a warm jacket", "Fog may slow the roads down"},
+		},
+		{
+			// the rain 12 hours from now is after the default window
+			name:     "rain after the window",
+			forecast: adviceForecast("si", append(repeat(mild, 12), with(mild, rain))...),
+			expected: []string{},
+		},
+		{
+			// the window from 12:00 to 13:00 misses the rain at 10:00 and
+			// 14:00
+			name:     "a window of the day",
+			forecast: adviceForecast("si", with(mild, rain), mild, mild, mild, with(mild, rain)),
+			window:   Window{Start: 12 * time.Hour, End: 13 * time.Hour},
+			expected: []string{},
+		},
+		{
+			name:     "a window of the day with rain",
+			forecast: adviceForecast("si", mild, mild, with(mild, rain), mild),
+			window:   Window{Start: 12 * time.Hour, End: 13 * time.Hour},
+			expected: []string{"Take an umbrella"},
+		},
+		{
+			name:     "custom rules",
+			forecast: adviceForecast("si", mild, with(mild, func(w *Weather) { w.PrecipProbability = 0.2 })),
+			rules:    custom,
+			expected: []string{"Go for a run", "Stay in"},
+		},
+	}
+
+	for _, tc := range testCases {
+		advice := Advise(tc.forecast, tc.rules, tc.window)
+		if !reflect.DeepEqual(advice.Items, tc.expected) {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.expected, advice.Items)
+		}
+	}
+}
+
+// repeat returns n copies of the weather.
+func repeat(weather Weather, n int) []Weather {
+	data := make([]Weather, n)
+	for i := range data {
+		data[i] = weather
+	}
+	return data
+}
@@ -0,0 +1,199 @@
+package forecast
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/genuinetools/weather/astro"
+	"github.com/mitchellh/colorstring"
+)
+
+// astronomy holds the times of the sun and the phase of the moon for a day.
+type astronomy struct {
+	// sunrise and sunset are zero if the sun does not rise or set.
+	sunrise, sunset int64
+	// polar describes the day if the sun does not rise or set, and
+	// alwaysUp is true if it does not set.
+	polar    string
+	alwaysUp bool
+	// dayLength and change are the length of the day and how much longer
+	// it is than the day before.
+	dayLength, change time.Duration
+	// golden are the morning and evening golden hours.
+	golden    [2][2]int64
+	hasGolden bool
+	moonPhase float64
+}
+
+// getAstronomy returns the astronomy for the day of the forecast. The
+// sunrise, sunset and moon phase are computed from the coordinates of the
+// forecast if the provider does not return them, and the golden hours
+// always are.
+func getAstronomy(forecast Forecast, day Weather) (astronomy, bool) {
+	lat, lng := forecast.Latitude, forecast.Longitude
+	if day.SunriseTime == 0 && lat == 0 && lng == 0 {
+		return astronomy{}, false
+	}
+
+	date := time.Unix(day.Time, 0).In(forecast.Location())
+	a := astronomy{
+		sunrise:   day.SunriseTime,
+		sunset:    day.SunsetTime,
+		moonPhase: day.MoonPhase,
+	}
+	// a provider without sunrise times has no moon phase either
+	if a.sunrise == 0 {
+		sunrise, sunset, ok := astro.Sunrise(date, lat, lng)
+		if ok {
+			a.sunrise, a.sunset = sunrise.Unix(), sunset.Unix()
+		}
+		a.moonPhase = astro.MoonPhase(date)
+	}
+
+	if a.sunrise == 0 {
+		// the sun is up at noon in the polar summer
+		a.alwaysUp = astro.NoonAltitude(date, lat, lng) >= astro.SunriseAltitude
+		a.polar = "The sun does not rise today"
+		if a.alwaysUp {
+			a.polar = "The sun does not set today"
+		}
+		return a, true
+	}
+
+	// compare the lengths of the days computed the same way, so the change
+	// is not lost in the difference between the provider and astro
+	a.dayLength = time.Duration(a.sunset-a.sunrise) * time.Second
+	today, okToday := dayLength(date, lat, lng)
+	yesterday, okYesterday := dayLength(date.AddDate(0, 0, -1), lat, lng)
+	if okToday && okYesterday {
+		a.change = today - yesterday
+	}
+
+	if rise, set, ok := astro.SunRiseSet(date, lat, lng, astro.GoldenHourAltitude); ok {
+		a.golden = [2][2]int64{{a.sunrise, rise.Unix()}, {set.Unix(), a.sunset}}
+		a.hasGolden = true
+	}
+
+	return a, true
+}
+
+// dayLength returns the time between sunrise and sunset on the date.
+func dayLength(date time.Time, lat, lng float64) (time.Duration, bool) {
+	sunrise, sunset, ok := astro.Sunrise(date, lat, lng)
+	return sunset.Sub(sunrise), ok
+}
+
+// formatDuration formats the duration in hours and minutes, e.g. "10h 49m",
+// or minutes and seconds if it is under an hour, e.g. "1m 57s".
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	if d < time.Hour {
+		d = d.Round(time.Second)
+		return fmt.Sprintf("%dm %ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// printAstronomy prints the sunrise and sunset, the length of the day, the
+// golden hours and the phase of the moon for the day.
+func printAstronomy(w io.Writer, forecast Forecast, day Weather, tf timeFormat) {
+	a, ok := getAstronomy(forecast, day)
+	if !ok {
+		return
+	}
+
+	if a.polar != "" {
+		fmt.Fprintf(w, "  %s\n", a.polar)
+	} else {
+		fmt.Fprintf(w, "  Sunrise is at %s and sunset at %s\n", colorstring.Color("[bold]"+tf.time(a.sunrise)), colorstring.Color("[bold]"+tf.time(a.sunset)))
+
+		change := ""
+		switch {
+		case a.change >= time.Second:
+			change = fmt.Sprintf(", %s longer than yesterday", formatDuration(a.change))
+		case a.change <= -time.Second:
+			change = fmt.Sprintf(", %s shorter than yesterday", formatDuration(a.change))
+		}
+		fmt.Fprintf(w, "  The day is %s long%s\n", colorstring.Color("[bold]"+formatDuration(a.dayLength)), change)
+
+		if a.hasGolden {
+			clock := tf.clock()
+			fmt.Fprintf(w, "  The golden hours are %s and %s\n",
+				colorstring.Color("[bold]"+tf.format(a.golden[0][0], clock)+"-"+tf.format(a.golden[0][1], clock)),
+				colorstring.Color("[bold]"+tf.format(a.golden[1][0], clock)+"-"+tf.time(a.golden[1][1])))
+		}
+	}
+
+	fmt.Fprintf(w, "  The moon is %s\n\n", colorstring.Color(fmt.Sprintf("[bold]%s %s", astro.PhaseGlyph(a.moonPhase), astro.PhaseName(a.moonPhase))))
+}
+
+// PrintSun prints the times of the sun and the moon on the date for the
+// coordinates and time zone of the forecast, which needs no other data,
+// and where the sun is now if the date is today.
+func PrintSun(w io.Writer, forecast Forecast, place string, date time.Time, opts Options) error {
+	tf := newTimeFormat(forecast, opts)
+	lat, lng := forecast.Latitude, forecast.Longitude
+	date = date.In(tf.loc)
+
+	fmt.Fprintf(w, "Sun and moon in %s on %s\n\n", colorstring.Color("[green]"+place), colorstring.Color("[magenta]"+tf.date(date.Unix())))
+
+	row := func(label, value string) {
+		fmt.Fprintf(w, "  %-20s %s\n", label, value)
+	}
+	at := func(t time.Time) string {
+		if t.IsZero() {
+			return "none"
+		}
+		return colorstring.Color("[bold]" + tf.time(t.Unix()))
+	}
+
+	sun := astro.Sun(date, lat, lng)
+	row("Astronomical dawn", at(sun.AstronomicalDawn))
+	row("Nautical dawn", at(sun.NauticalDawn))
+	row("Civil dawn", at(sun.CivilDawn))
+	row("Sunrise", at(sun.Sunrise))
+	row("Golden hour ends", at(sun.GoldenHourEnd))
+	row("Solar noon", fmt.Sprintf("%s, %.1f° high", at(sun.SolarNoon), astro.SunPosition(sun.SolarNoon, lat, lng).Altitude))
+	row("Golden hour starts", at(sun.GoldenHour))
+	row("Sunset", at(sun.Sunset))
+	row("Civil dusk", at(sun.CivilDusk))
+	row("Nautical dusk", at(sun.NauticalDusk))
+	row("Astronomical dusk", at(sun.AstronomicalDusk))
+
+	switch {
+	case !sun.Sunrise.IsZero() && !sun.Sunset.IsZero():
+		row("Day length", formatDuration(sun.Sunset.Sub(sun.Sunrise)))
+	case astro.NoonAltitude(date, lat, lng) >= astro.SunriseAltitude:
+		row("Day length", "24h, the sun does not set")
+	default:
+		row("Day length", "0h, the sun does not rise")
+	}
+
+	now := time.Now().In(tf.loc)
+	if y, m, d := now.Date(); date.Year() == y && date.Month() == m && date.Day() == d {
+		p := astro.SunPosition(now, lat, lng)
+		row("Sun now", fmt.Sprintf("%.1f° high, %.0f° %s", p.Altitude, p.Azimuth, getBearingDetails(p.Azimuth)))
+	}
+	fmt.Fprintln(w)
+
+	moon := astro.MoonRiseSet(date, lat, lng)
+	switch {
+	case moon.AlwaysUp:
+		row("Moonrise", "up all day")
+	case moon.AlwaysDown:
+		row("Moonrise", "down all day")
+	default:
+		row("Moonrise", at(moon.Rise))
+		row("Moonset", at(moon.Set))
+	}
+	// the phase at noon describes the day
+	illumination := astro.MoonIllumination(sun.SolarNoon)
+	row("Moon", fmt.Sprintf("%s %s, %.0f%% lit", astro.PhaseGlyph(illumination.Phase), astro.PhaseName(illumination.Phase), illumination.Fraction*100))
+	fmt.Fprintln(w)
+
+	return nil
+}
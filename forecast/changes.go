@@ -0,0 +1,81 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+)
+
+// ChangeThresholds describe how big a difference between two forecasts has
+// to be before it is reported as a change.
+type ChangeThresholds struct {
+	// TemperatureSwing is the change in the current temperature, in the
+	// forecast's units, that is reported.
+	TemperatureSwing float64
+	// PrecipProbability is the precipitation probability (0-1) that is
+	// reported when the current or next hour crosses it in either direction.
+	PrecipProbability float64
+}
+
+// Changes returns human readable descriptions of the notable differences
+// between the previous and current forecast for the same location.
+func Changes(previous, current Forecast, thresholds ChangeThresholds) []string {
+	unitsFormat := current.Units()
+	tf := newTimeFormat(current, Options{})
+	changes := []string{}
+
+	// new or updated alerts
+	seen := map[string]Alert{}
+	for _, alert := range previous.Alerts {
+		seen[alertKey(alert)] = alert
+	}
+	for _, alert := range current.Alerts {
+		old, ok := seen[alertKey(alert)]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("New alert: %s", alert.Title))
+		case old.Expires != alert.Expires:
+			changes = append(changes, fmt.Sprintf("Alert updated: %s now expires %s", alert.Title, tf.dateTime(alert.Expires)))
+		}
+	}
+
+	// temperature swing
+	diff := current.Currently.Temperature - previous.Currently.Temperature
+	if thresholds.TemperatureSwing > 0 && math.Abs(diff) >= thresholds.TemperatureSwing {
+		direction := "rose"
+		if diff < 0 {
+			direction = "dropped"
+		}
+		changes = append(changes, fmt.Sprintf("Temperature %s %.1f%s to %v%s", direction, math.Abs(diff), unitsFormat.Degrees, current.Currently.Temperature, unitsFormat.Degrees))
+	}
+
+	// precipitation probability crossing the threshold
+	if thresholds.PrecipProbability > 0 {
+		before, after := nextPrecipProbability(previous), nextPrecipProbability(current)
+		switch {
+		case before < thresholds.PrecipProbability && after >= thresholds.PrecipProbability:
+			changes = append(changes, fmt.Sprintf("Rain chance rose to %.0f%%", after*100))
+		case before >= thresholds.PrecipProbability && after < thresholds.PrecipProbability:
+			changes = append(changes, fmt.Sprintf("Rain chance fell to %.0f%%", after*100))
+		}
+	}
+
+	return changes
+}
+
+// alertKey returns the key used to tell alerts apart between forecasts.
+func alertKey(alert Alert) string {
+	if alert.URI != "" {
+		return alert.URI
+	}
+	return alert.Title
+}
+
+// nextPrecipProbability returns the highest precipitation probability for
+// right now and the next hour.
+func nextPrecipProbability(forecast Forecast) float64 {
+	p := forecast.Currently.PrecipProbability
+	if len(forecast.Hourly.Data) > 1 {
+		p = math.Max(p, forecast.Hourly.Data[1].PrecipProbability)
+	}
+	return p
+}
@@ -0,0 +1,166 @@
+package forecast
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/genuinetools/weather/chart"
+	"github.com/mitchellh/colorstring"
+)
+
+const (
+	chartHeight      = 8
+	chartPrecipRows  = 4
+	chartLabelWidth  = 7
+	chartLabelMargin = 2
+)
+
+// printCharts prints charts of the hourly temperature and precipitation
+// and, if days are requested, the daily highs and lows.
+func printCharts(w io.Writer, forecast Forecast, opts Options) error {
+	unitsFormat := forecast.Units()
+	tf := newTimeFormat(forecast, opts)
+	width := opts.width() - chartLabelWidth - chartLabelMargin
+	if width < 10 {
+		return nil
+	}
+
+	// the hourly charts default to the whole 48 hours
+	hours := opts.Hours
+	if hours < 1 || hours > MaxHours {
+		hours = MaxHours
+	}
+	if hours > len(forecast.Hourly.Data) {
+		hours = len(forecast.Hourly.Data)
+	}
+
+	if hours > 1 {
+		data := forecast.Hourly.Data[:hours]
+		temps, feels, precip := make([]float64, hours), make([]float64, hours), make([]float64, hours)
+		labels := make([]string, hours)
+		for i, hourly := range data {
+			temps[i] = hourly.Temperature
+			feels[i] = hourly.ApparentTemperature
+			precip[i] = hourly.PrecipProbability * 100
+			labels[i] = tf.hour(hourly.Time)
+		}
+
+		// only label every few hours so the labels fit
+		step := labelStep(width, hours, 5, []int{1, 2, 3, 4, 6, 12})
+		for i := range labels {
+			if tf.hourOfDay(data[i].Time)%step != 0 {
+				labels[i] = ""
+			}
+		}
+
+		legend := colorstring.Color("[magenta]━ temperature[reset]  [cyan]━ feels like")
+		if opts.ASCII {
+			legend = "* temperature  + feels like"
+		}
+		fmt.Fprintf(w, "Temperature (%s)  %s\n", unitsFormat.Degrees, legend)
+		rows, min, max := chart.Line([]chart.Series{
+			{Values: feels, Color: "cyan", Char: '+'},
+			{Values: temps, Color: "magenta", Char: '*'},
+		}, width, chartHeight, opts.ASCII)
+		writeChartRows(w, rows, formatDegrees(max, unitsFormat), formatDegrees(min, unitsFormat), opts)
+		writeChartAxis(w, labels, width, true)
+
+		fmt.Fprintln(w, "Precipitation chance")
+		rows = chart.Bars(precip, 100, "blue", width, chartPrecipRows, opts.ASCII)
+		writeChartRows(w, rows, "100%", "0%", opts)
+		writeChartAxis(w, labels, width, false)
+	}
+
+	// daily highs and lows, ignoring the current day like PrintDaily
+	days := opts.Days
+	if days > len(forecast.Daily.Data)-1 {
+		days = len(forecast.Daily.Data) - 1
+	}
+	if days > 0 {
+		data := forecast.Daily.Data[1 : days+1]
+		lows, highs := make([]float64, days), make([]float64, days)
+		labels := make([]string, days)
+		for i, daily := range data {
+			lows[i] = daily.TemperatureMin
+			highs[i] = daily.TemperatureMax
+			labels[i] = tf.weekday(daily.Time)
+		}
+
+		fmt.Fprintf(w, "Daily high and low (%s)\n", unitsFormat.Degrees)
+		rows, min, max := chart.Bands(lows, highs, "blue", width, chartHeight, opts.ASCII)
+		writeChartRows(w, rows, formatDegrees(max, unitsFormat), formatDegrees(min, unitsFormat), opts)
+		writeChartAxis(w, labels, width, false)
+	}
+
+	return nil
+}
+
+// writeChartRows writes the rows of a chart with the top and bottom values
+// labelled on the y axis.
+func writeChartRows(w io.Writer, rows []string, top, bottom string, opts Options) {
+	axis := "┤"
+	if opts.ASCII {
+		axis = "|"
+	}
+	for i, row := range rows {
+		label := ""
+		switch i {
+		case 0:
+			label = top
+		case len(rows) - 1:
+			label = bottom
+		}
+		fmt.Fprintf(w, "%*s %s%s\n", chartLabelWidth, label, axis, row)
+	}
+}
+
+// writeChartAxis writes the labels under a chart, at the position of the
+// entry they belong to. Lines are plotted from edge to edge while bars
+// split the width evenly.
+func writeChartAxis(w io.Writer, labels []string, width int, line bool) {
+	axis := []rune(strings.Repeat(" ", width+utf8.RuneCountInString(labels[0])+1))
+	next := 0
+	for i, label := range labels {
+		if label == "" {
+			continue
+		}
+		chars := []rune(label)
+
+		x := i * width / len(labels)
+		if line && len(labels) > 1 {
+			x = i * (width - 1) / (len(labels) - 1)
+		}
+		// center the label under wide bars
+		if size := width / len(labels); !line && size > len(chars) {
+			x += (size - len(chars)) / 2
+		}
+		// skip labels that would overlap the previous one
+		if x < next {
+			continue
+		}
+		for j, r := range chars {
+			if x+j < len(axis) {
+				axis[x+j] = r
+			}
+		}
+		next = x + len(chars) + 1
+	}
+	fmt.Fprintf(w, "%*s  %s\n\n", chartLabelWidth, "", strings.TrimRight(string(axis), " "))
+}
+
+// labelStep returns the smallest step from steps so labels of size fit
+// between the entries drawn across width.
+func labelStep(width, entries, size int, steps []int) int {
+	for _, step := range steps {
+		if step*width/entries >= size+1 {
+			return step
+		}
+	}
+	return steps[len(steps)-1]
+}
+
+func formatDegrees(f float64, unitsFormat UnitMeasures) string {
+	return fmt.Sprintf("%.0f%s", f, unitsFormat.Degrees)
+}
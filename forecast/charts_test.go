@@ -0,0 +1,74 @@
+package forecast
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteChartAxis(t *testing.T) {
+	testCases := []struct {
+		labels   []string
+		width    int
+		line     bool
+		expected string
+	}{
+		{
+			labels:   []string{"Mon", "Tue", "Wed", "Thu"},
+			width:    20,
+			expected: " Mon  Tue  Wed  Thu",
+		},
+		// multibyte labels take a column per character
+		{
+			labels:   []string{"lun", "mar", "mié", "jue", "vie", "sáb", "dom"},
+			width:    35,
+			expected: " lun  mar  mié  jue  vie  sáb  dom",
+		},
+		{
+			labels:   []string{"9a", "", "", "", "1p", "", "", ""},
+			width:    16,
+			line:     true,
+			expected: "9a      1p",
+		},
+		// labels that would overlap the previous one are skipped
+		{
+			labels:   []string{"mié", "jue"},
+			width:    4,
+			expected: "mié",
+		},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		writeChartAxis(&buf, tc.labels, tc.width, tc.line)
+		expected := strings.Repeat(" ", chartLabelWidth+2) + tc.expected + "\n\n"
+		if buf.String() != expected {
+			t.Errorf("labels %q across %d: expected\n%q\ngot\n%q", tc.labels, tc.width, expected, buf.String())
+		}
+	}
+}
+
+func TestPrintChartsLocale(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printCharts(&buf, testForecast(), Options{Days: 7, Width: 80, Locale: "es-ES"}); err != nil {
+		t.Fatal(err)
+	}
+	out := ansiRegex.ReplaceAllString(buf.String(), "")
+
+	// the fixture's days from Saturday are labelled in order, evenly spaced
+	lines := strings.Split(out, "\n")
+	var axis string
+	for i, line := range lines {
+		if strings.Contains(line, "sáb") && i > 0 {
+			axis = line
+		}
+	}
+	if axis == "" {
+		t.Fatalf("expected an axis with the weekdays in Spanish, got:\n%s", out)
+	}
+	fields := strings.Fields(axis)
+	expected := []string{"sáb", "dom", "lun", "mar", "mié", "jue"}
+	if strings.Join(fields, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected the weekdays %q, got %q", expected, fields)
+	}
+}
@@ -0,0 +1,108 @@
+package forecast
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/genuinetools/weather/comfort"
+	"github.com/genuinetools/weather/units"
+	"github.com/mitchellh/colorstring"
+)
+
+// thresholds returns the comfort thresholds of the options, or the default
+// ones if they are not set.
+func (opts Options) thresholds() comfort.Thresholds {
+	if opts.Comfort == (comfort.Thresholds{}) {
+		return comfort.DefaultThresholds
+	}
+	return opts.Comfort
+}
+
+// comfortMetrics returns how the weather feels, with the temperatures in
+// the units of the system. A day is judged by its high. It returns false
+// for weather without a temperature and humidity, e.g. minutely data.
+func comfortMetrics(weather Weather, system units.System, t comfort.Thresholds) (comfort.Metrics, bool) {
+	temperature := weather.Temperature
+	if weather.TemperatureMaxTime != 0 {
+		temperature = weather.TemperatureMax
+	}
+	if weather.Humidity == 0 {
+		return comfort.Metrics{}, false
+	}
+
+	celsius := func(v float64) float64 {
+		return units.TemperatureIn(v, system.Temperature).In(units.Celsius)
+	}
+	m := comfort.Compute(celsius(temperature), celsius(weather.DewPoint), weather.Humidity,
+		units.SpeedIn(weather.WindSpeed, system.Speed).In(units.MetersPerSecond), t)
+
+	for _, v := range []*float64{&m.HeatIndex, &m.WindChill, &m.WBGT, &m.FeelsLike} {
+		*v = units.Round(units.TemperatureIn(*v, units.Celsius).In(system.Temperature))
+	}
+	m.Humidex = units.Round(m.Humidex)
+	return m, true
+}
+
+// WithComfort returns the forecast with the comfort metrics of the current
+// weather and each hour and day, for the JSON and template outputs.
+func (f Forecast) WithComfort(t comfort.Thresholds) Forecast {
+	system := f.System()
+	add := func(w Weather) Weather {
+		if m, ok := comfortMetrics(w, system, t); ok {
+			w.Comfort = &m
+		}
+		return w
+	}
+
+	f.Currently = add(f.Currently)
+	for _, block := range []*TimeDelimited{&f.Hourly, &f.Daily} {
+		data := make([]Weather, len(block.Data))
+		for i, weather := range block.Data {
+			data[i] = add(weather)
+		}
+		block.Data = data
+	}
+	return f
+}
+
+// printComfort prints how the weather feels and the heat index or wind
+// chill where they apply. The humidex and wet-bulb globe temperature are
+// only shown in the heat.
+func printComfort(w io.Writer, weather Weather, m comfort.Metrics, system units.System) {
+	degrees := unitMeasures(system).Degrees
+	temperature := weather.Temperature
+	if weather.TemperatureMaxTime != 0 {
+		temperature = weather.TemperatureMax
+	}
+	differs := func(v float64) bool {
+		return math.Abs(v-temperature) >= 0.05
+	}
+
+	var details []string
+	if differs(m.HeatIndex) {
+		details = append(details,
+			fmt.Sprintf("a heat index of %s", colorstring.Color(fmt.Sprintf("[bold]%.1f%s", m.HeatIndex, degrees))),
+			fmt.Sprintf("a humidex of %s", colorstring.Color(fmt.Sprintf("[bold]%.0f", m.Humidex))),
+			fmt.Sprintf("a WBGT of %s", colorstring.Color(fmt.Sprintf("[bold]%.1f%s", m.WBGT, degrees))))
+	}
+	if differs(m.WindChill) {
+		details = append(details, fmt.Sprintf("a wind chill of %s", colorstring.Color(fmt.Sprintf("[bold]%.1f%s", m.WindChill, degrees))))
+	}
+
+	color := "[bold]"
+	if m.Category == comfort.DangerousHeat || m.Category == comfort.DangerousCold {
+		color = "[bold][red]"
+	}
+	feels := "  It feels " + colorstring.Color(color+m.Category)
+	switch len(details) {
+	case 0:
+		fmt.Fprintln(w, feels)
+	case 1:
+		fmt.Fprintf(w, "%s, with %s\n", feels, details[0])
+	default:
+		last := len(details) - 1
+		fmt.Fprintf(w, "%s, with %s and %s\n", feels, strings.Join(details[:last], ", "), details[last])
+	}
+}
@@ -0,0 +1,110 @@
+package forecast
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mitchellh/colorstring"
+)
+
+// compareHours is how many hours ahead the rain chance of the comparison
+// is the highest of.
+const compareHours = 12
+
+// Comparison is the forecast for a place in a comparison of places.
+type Comparison struct {
+	Place    string
+	Forecast Forecast
+}
+
+// PrintComparison prints a compact table of the current weather, the day
+// and the rain chance of each place, a row per place.
+func PrintComparison(w io.Writer, places []Comparison, opts Options) error {
+	headers := []string{"Location", "Now", "Feels", "High/Low", "Rain", "Wind", "Alerts", "Summary"}
+
+	rows := make([][]string, len(places))
+	for i, place := range places {
+		fc := place.Forecast
+		unitsFormat := fc.Units()
+		degrees := func(f float64) string {
+			return fmt.Sprintf("%.0f%s", f, unitsFormat.Degrees)
+		}
+
+		highLow := "-"
+		if len(fc.Daily.Data) > 0 {
+			highLow = fmt.Sprintf("%.0f/%s", fc.Daily.Data[0].TemperatureMax, degrees(fc.Daily.Data[0].TemperatureMin))
+		}
+		rain := fc.Currently.PrecipProbability
+		for j := 0; j < compareHours && j < len(fc.Hourly.Data); j++ {
+			rain = math.Max(rain, fc.Hourly.Data[j].PrecipProbability)
+		}
+		alerts := ""
+		if n := len(fc.Alerts); n > 0 && !opts.IgnoreAlerts {
+			alerts = fmt.Sprintf("%d", n)
+		}
+
+		rows[i] = []string{
+			place.Place,
+			degrees(fc.Currently.Temperature),
+			degrees(fc.Currently.ApparentTemperature),
+			highLow,
+			fmt.Sprintf("%.0f%%", rain*100),
+			fmt.Sprintf("%.0f %s %s", fc.Currently.WindSpeed, unitsFormat.Speed, getBearingDetails(fc.Currently.WindBearing)),
+			alerts,
+			fc.Currently.Summary,
+		}
+	}
+
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = utf8.RuneCountInString(header)
+		for _, row := range rows {
+			if n := utf8.RuneCountInString(row[i]); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	// the summary takes what is left of the width
+	last := len(headers) - 1
+	used := 0
+	for _, width := range widths[:last] {
+		used += width + 2
+	}
+	if left := opts.width() - used; left < widths[last] {
+		widths[last] = left
+		if widths[last] < len(headers[last]) {
+			widths[last] = len(headers[last])
+		}
+	}
+
+	line := func(cells []string, color func(i int, cell string) string) {
+		var b strings.Builder
+		for i, cell := range cells {
+			cell = truncate(cell, widths[i])
+			padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			if i < last {
+				padding += "  "
+			}
+			b.WriteString(color(i, cell) + padding)
+		}
+		fmt.Fprintln(w, strings.TrimRight(b.String(), " "))
+	}
+
+	line(headers, func(i int, cell string) string { return colorstring.Color("[bold]" + cell) })
+	for _, row := range rows {
+		line(row, func(i int, cell string) string {
+			switch {
+			case i == 0:
+				return colorstring.Color("[green]" + cell)
+			case i == 6 && cell != "":
+				return colorstring.Color("[red]" + cell)
+			}
+			return cell
+		})
+	}
+
+	return nil
+}
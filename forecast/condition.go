@@ -0,0 +1,189 @@
+package forecast
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// conditionRegex matches expressions like:
+//   temperature < 0
+//   precipProbability > 0.5 within 3h
+//   icon == snow within 2d
+var conditionRegex = regexp.MustCompile(`^\s*(\w+)\s*(<=|>=|==|!=|<|>)\s*(\S+)(?:\s+within\s+(\d+)\s*([hd]))?\s*$`)
+
+// weatherFields maps the lowercased json names of the numeric and string
+// Weather fields to their index in the struct.
+var weatherFields = func() map[string]int {
+	fields := map[string]int{}
+	t := reflect.TypeOf(Weather{})
+	for i := 0; i < t.NumField(); i++ {
+		switch t.Field(i).Type.Kind() {
+		case reflect.Float32, reflect.Float64,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.String:
+		default:
+			continue
+		}
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		fields[strings.ToLower(name)] = i
+	}
+	return fields
+}()
+
+// Condition is a simple threshold expression evaluated against the
+// currently, hourly or daily forecast data.
+type Condition struct {
+	// Expr is the expression the condition was parsed from.
+	Expr string
+	// Field is the json name of the Weather field to compare.
+	Field string
+	// Op is the comparison operator.
+	Op string
+	// Value is the value to compare numeric fields against.
+	Value float64
+	// Text is the value to compare string fields against.
+	Text string
+	// Within is how far ahead to look, zero means only the current weather.
+	Within time.Duration
+	// Daily is true if the daily rather than the hourly data should be
+	// searched.
+	Daily bool
+
+	field int
+}
+
+// ParseCondition parses an expression in the form
+// "<field> <op> <value> [within <n>h|<n>d]".
+func ParseCondition(expr string) (Condition, error) {
+	m := conditionRegex.FindStringSubmatch(expr)
+	if m == nil {
+		return Condition{}, fmt.Errorf("invalid condition %q: expected \"<field> <op> <value> [within <n>h|<n>d]\"", expr)
+	}
+
+	c := Condition{
+		Expr:  strings.TrimSpace(expr),
+		Field: m[1],
+		Op:    m[2],
+		Text:  m[3],
+	}
+
+	index, ok := weatherFields[strings.ToLower(c.Field)]
+	if !ok {
+		return c, fmt.Errorf("invalid condition %q: unknown field %q", expr, c.Field)
+	}
+	c.field = index
+
+	switch reflect.TypeOf(Weather{}).Field(index).Type.Kind() {
+	case reflect.String:
+		if c.Op != "==" && c.Op != "!=" {
+			return c, fmt.Errorf("invalid condition %q: %s can only be compared with == or !=", expr, c.Field)
+		}
+	default:
+		v, err := strconv.ParseFloat(c.Text, 64)
+		if err != nil {
+			return c, fmt.Errorf("invalid condition %q: %q is not a number", expr, c.Text)
+		}
+		c.Value = v
+	}
+
+	if m[4] != "" {
+		n, err := strconv.Atoi(m[4])
+		if err != nil {
+			return c, fmt.Errorf("invalid condition %q: %v", expr, err)
+		}
+		c.Within = time.Duration(n) * time.Hour
+		if m[5] == "d" {
+			c.Within *= 24
+			c.Daily = true
+		}
+	}
+
+	return c, nil
+}
+
+// Match reports whether the condition holds for the forecast, and returns
+// the first data point it held for.
+func (c Condition) Match(forecast Forecast) (Weather, bool) {
+	for _, weather := range c.candidates(forecast) {
+		if c.matches(weather) {
+			return weather, true
+		}
+	}
+	return forecast.Currently, false
+}
+
+// FieldValue returns the value of the field the condition compares
+// formatted as a string.
+func (c Condition) FieldValue(weather Weather) string {
+	return fmt.Sprintf("%v", reflect.ValueOf(weather).Field(c.field).Interface())
+}
+
+// candidates returns the data points the condition should be evaluated
+// against.
+func (c Condition) candidates(forecast Forecast) []Weather {
+	if c.Within == 0 {
+		return []Weather{forecast.Currently}
+	}
+
+	now := forecast.Currently.Time
+	if c.Daily {
+		// the days are whole days from today, not 24 hours from now, which
+		// would take in another day after midnight
+		days := int(c.Within / (24 * time.Hour))
+		data := []Weather{}
+		for _, daily := range forecast.Daily.Data {
+			if daily.Time+24*60*60 > now && len(data) < days {
+				data = append(data, daily)
+			}
+		}
+		return data
+	}
+
+	end := now + int64(c.Within/time.Second)
+	data := []Weather{forecast.Currently}
+	for _, hourly := range forecast.Hourly.Data {
+		if hourly.Time+60*60 > now && hourly.Time <= end {
+			data = append(data, hourly)
+		}
+	}
+	return data
+}
+
+func (c Condition) matches(weather Weather) bool {
+	v := reflect.ValueOf(weather).Field(c.field)
+	if v.Kind() == reflect.String {
+		equal := strings.EqualFold(v.String(), c.Text)
+		if c.Op == "==" {
+			return equal
+		}
+		return !equal
+	}
+
+	var f float64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f = float64(v.Int())
+	default:
+		f = v.Float()
+	}
+
+	switch c.Op {
+	case "<":
+		return f < c.Value
+	case "<=":
+		return f <= c.Value
+	case ">":
+		return f > c.Value
+	case ">=":
+		return f >= c.Value
+	case "==":
+		return f == c.Value
+	case "!=":
+		return f != c.Value
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package forecast
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/genuinetools/weather/comfort"
+)
+
+func TestParseCondition(t *testing.T) {
+	testCases := []struct {
+		expr string
+		err  string
+	}{
+		{expr: "temperature < 0"},
+		{expr: "precipProbability > 0.5 within 3h"},
+		{expr: "icon == snow within 2d"},
+		{expr: "uvIndex >= 6"},
+		{expr: "icon > snow", err: "can only be compared with == or !="},
+		{expr: "temperature < cold", err: "is not a number"},
+		{expr: "nothing > 1", err: `unknown field "nothing"`},
+		// comfort is a pointer to the comfort metrics, not a value
+		{expr: "comfort > 1", err: `unknown field "comfort"`},
+		{expr: "temperature", err: "expected"},
+	}
+
+	for _, tc := range testCases {
+		_, err := ParseCondition(tc.expr)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("ParseCondition(%q): unexpected error: %v", tc.expr, err)
+		case tc.err != "" && err == nil:
+			t.Errorf("ParseCondition(%q): expected an error containing %q", tc.expr, tc.err)
+		case tc.err != "" && !strings.Contains(err.Error(), tc.err):
+			t.Errorf("ParseCondition(%q): expected an error containing %q, got %v", tc.expr, tc.err, err)
+		}
+	}
+}
+
+func TestConditionMatch(t *testing.T) {
+	fc := Forecast{
+		Currently: Weather{
+			Time:        1000,
+			Temperature: 5,
+			Icon:        "cloudy",
+			Comfort:     &comfort.Metrics{},
+		},
+		Hourly: TimeDelimited{Data: []Weather{
+			{Time: 1000, Temperature: 5, Icon: "cloudy"},
+			{Time: 1000 + 60*60, Temperature: -1, Icon: "snow"},
+			{Time: 1000 + 2*60*60, Temperature: -3, Icon: "snow", Comfort: &comfort.Metrics{}},
+		}},
+	}
+
+	testCases := []struct {
+		expr  string
+		match bool
+		time  int64
+	}{
+		{expr: "temperature < 0", match: false},
+		{expr: "temperature < 0 within 3h", match: true, time: 1000 + 60*60},
+		{expr: "icon == SNOW within 3h", match: true, time: 1000 + 60*60},
+		{expr: "icon != cloudy", match: false},
+		{expr: "temperature >= 5", match: true, time: 1000},
+	}
+
+	for _, tc := range testCases {
+		c, err := ParseCondition(tc.expr)
+		if err != nil {
+			t.Fatalf("ParseCondition(%q): %v", tc.expr, err)
+		}
+		weather, ok := c.Match(fc)
+		if ok != tc.match {
+			t.Errorf("%q: expected match %t, got %t", tc.expr, tc.match, ok)
+			continue
+		}
+		if ok && weather.Time != tc.time {
+			t.Errorf("%q: expected a match at %d, got %d", tc.expr, tc.time, weather.Time)
+		}
+	}
+}
+
+// At 9am the days within 2d are today and tomorrow, not the day after
+// tomorrow that starts within 48 hours.
+func TestConditionMatchDays(t *testing.T) {
+	fc := testForecast()
+	day := func(i int) int64 { return fc.Daily.Data[i].Time }
+
+	testCases := []struct {
+		expr  string
+		match bool
+		time  int64
+	}{
+		{expr: "icon == rain within 1d", match: true, time: day(0)},
+		{expr: "temperatureMax >= 13 within 1d", match: false},
+		{expr: "temperatureMax >= 13 within 2d", match: true, time: day(1)},
+		{expr: "temperatureMax >= 14 within 2d", match: false},
+		{expr: "temperatureMax >= 14 within 3d", match: true, time: day(2)},
+		{expr: "temperatureMax >= 18 within 7d", match: true, time: day(6)},
+		{expr: "temperatureMax >= 19 within 10d", match: false},
+	}
+
+	for _, tc := range testCases {
+		c, err := ParseCondition(tc.expr)
+		if err != nil {
+			t.Fatalf("ParseCondition(%q): %v", tc.expr, err)
+		}
+		weather, ok := c.Match(fc)
+		if ok != tc.match {
+			t.Errorf("%q: expected match %t, got %t", tc.expr, tc.match, ok)
+			continue
+		}
+		if ok && weather.Time != tc.time {
+			t.Errorf("%q: expected a match at %d, got %d", tc.expr, tc.time, weather.Time)
+		}
+	}
+}
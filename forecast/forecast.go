@@ -3,8 +3,13 @@ package forecast
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
+
+	"github.com/genuinetools/weather/comfort"
+	"github.com/genuinetools/weather/units"
 )
 
 // response from https://api.darksky.net/forecast/
@@ -136,8 +141,13 @@ type Forecast struct {
 	Hourly    TimeDelimited `json:"hourly"`
 	Latitude  float64       `json:"latitude"`
 	Longitude float64       `json:"longitude"`
+	Minutely  TimeDelimited `json:"minutely"`
 	Offset    float64       `json:"offset"`
 	Timezone  string        `json:"timezone"`
+
+	// Advice is derived from the hourly data for the outputs, it is not
+	// returned by the API.
+	Advice *Advice `json:"advice,omitempty"`
 }
 
 // Alert contains any weather alerts happening at the location.
@@ -152,6 +162,8 @@ type Alert struct {
 // Flags describes the flags on a forecast.
 type Flags struct {
 	Units string `json:"units"`
+	// System is set once the forecast is converted to other units.
+	System *units.System `json:"system,omitempty"`
 }
 
 // Weather describes details about the weather for the location.
@@ -165,6 +177,7 @@ type Weather struct {
 	DewPoint                   float64 `json:"dewPoint"`
 	Humidity                   float64 `json:"humidity"`
 	Icon                       string  `json:"icon"`
+	MoonPhase                  float64 `json:"moonPhase"`
 	NearestStormDistance       float64 `json:"nearestStormDistance"`
 	NearestStormBearing        float64 `json:"nearestStormBearing"`
 	Ozone                      float64 `json:"ozone"`
@@ -183,9 +196,15 @@ type Weather struct {
 	TemperatureMin             float64 `json:"temperatureMin"`
 	TemperatureMinTime         int64   `json:"temperatureMinTime"`
 	Time                       int64   `json:"time"`
+	UVIndex                    float64 `json:"uvIndex"`
 	Visibility                 float64 `json:"visibility"`
 	WindBearing                float64 `json:"windBearing"`
+	WindGust                   float64 `json:"windGust"`
 	WindSpeed                  float64 `json:"windSpeed"`
+
+	// Comfort is derived from the other fields for the outputs, it is not
+	// returned by the API. A day's is for its high.
+	Comfort *comfort.Metrics `json:"comfort,omitempty"`
 }
 
 // TimeDelimited describes the data for the time series.
@@ -201,6 +220,26 @@ type Request struct {
 	Longitude float64  `json:"lng"`
 	Units     string   `json:"units"`
 	Exclude   []string `json:"exclude"`
+	// Country is the ISO 3166 country code of the location, used to pick
+	// the units when they are "auto".
+	Country string `json:"country,omitempty"`
+	// Time is the unix time of a day to get the weather for, in the past
+	// or future, rather than the forecast from now.
+	Time int64 `json:"time,omitempty"`
+}
+
+// Location returns the time zone of the forecast location, falling back
+// to the offset if the time zone is unknown.
+func (f Forecast) Location() *time.Location {
+	if f.Timezone != "" {
+		if loc, err := time.LoadLocation(f.Timezone); err == nil {
+			return loc
+		}
+	}
+	if f.Offset != 0 || f.Timezone != "" {
+		return time.FixedZone(fmt.Sprintf("UTC%+g", f.Offset), int(f.Offset*60*60))
+	}
+	return time.Local
 }
 
 // Get performs a request to get the forecast data for a location.
@@ -241,3 +280,76 @@ func Get(uri string, data Request) (forecast Forecast, err error) {
 
 	return forecast, nil
 }
+
+// ErrNoBatch is returned by GetBatch if the server has no batch endpoint.
+var ErrNoBatch = errors.New("the server does not take batches of forecast requests")
+
+// BatchResult is the forecast for one of the requests of a batch, or the
+// error getting it.
+type BatchResult struct {
+	Forecast *Forecast `json:"forecast,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// GetBatch performs a request to get the forecast data for many locations
+// at once, the results in the order of the requests.
+func GetBatch(uri string, data []Request) ([]BatchResult, error) {
+	// create json data
+	jsonByte, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling forecast batch json failed: %v", err)
+	}
+
+	// send the request
+	req, err := http.NewRequest("POST", uri, bytes.NewReader(jsonByte))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http request to %s failed: %s", req.URL, err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNoBatch
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http request to %s failed with status code: %v", req.URL, resp.StatusCode)
+	}
+
+	// decode the body, an object rather than a list is an error
+	var body json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding forecast batch response failed: %v", err)
+	}
+	if b := bytes.TrimSpace(body); len(b) > 0 && b[0] == '{' {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(b, &e); err != nil || e.Error == "" {
+			return nil, fmt.Errorf("decoding forecast batch response failed: %s", b)
+		}
+		return nil, fmt.Errorf("Forecast API response error: %s", e.Error)
+	}
+
+	var results []BatchResult
+	if err := json.Unmarshal(body, &results); err != nil {
+		return nil, fmt.Errorf("decoding forecast batch response failed: %v", err)
+	}
+	if len(results) != len(data) {
+		return nil, fmt.Errorf("forecast batch response has %d results for %d requests", len(results), len(data))
+	}
+	for i, result := range results {
+		if result.Error == "" && result.Forecast != nil && result.Forecast.Error != "" {
+			results[i].Error = result.Forecast.Error
+		}
+		if result.Error == "" && result.Forecast == nil {
+			results[i].Error = "no forecast in the batch response"
+		}
+	}
+	return results, nil
+}
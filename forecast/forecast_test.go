@@ -0,0 +1,71 @@
+package forecast
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGetBatch(t *testing.T) {
+	requests := []Request{{Latitude: 1, Longitude: 1}, {Latitude: 2, Longitude: 2}}
+
+	testCases := []struct {
+		status   int
+		body     string
+		expected []BatchResult
+		err      error
+	}{
+		{
+			status: http.StatusOK,
+			body:   `[{"forecast":{"latitude":1}},{"error":"request to the API failed"}]`,
+			expected: []BatchResult{
+				{Forecast: &Forecast{Latitude: 1}},
+				{Error: "request to the API failed"},
+			},
+		},
+		// errors from the API in the forecast itself, and no forecast
+		{
+			status: http.StatusOK,
+			body:   `[{"forecast":{"error":"daily usage limit exceeded"}},{}]`,
+			expected: []BatchResult{
+				{Forecast: &Forecast{Error: "daily usage limit exceeded"}, Error: "daily usage limit exceeded"},
+				{Error: "no forecast in the batch response"},
+			},
+		},
+		{
+			status: http.StatusNotFound,
+			body:   "404 page not found",
+			err:    ErrNoBatch,
+		},
+		{
+			status: http.StatusOK,
+			body:   `{"error":"A batch can hold at most 100 forecast requests, 101 were sent."}`,
+			err:    errors.New("Forecast API response error: A batch can hold at most 100 forecast requests, 101 were sent."),
+		},
+		{
+			status: http.StatusOK,
+			body:   `[{"forecast":{"latitude":1}}]`,
+			err:    errors.New("forecast batch response has 1 results for 2 requests"),
+		},
+	}
+
+	for _, tc := range testCases {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+			w.Write([]byte(tc.body))
+		}))
+		results, err := GetBatch(s.URL, requests)
+		s.Close()
+
+		// ErrNoBatch is the error itself, the others the same message
+		if err != tc.err && (err == nil || tc.err == nil || err.Error() != tc.err.Error()) {
+			t.Errorf("%s: expected the error %v, got %v", tc.body, tc.err, err)
+			continue
+		}
+		if !reflect.DeepEqual(results, tc.expected) {
+			t.Errorf("%s: expected %+v, got %+v", tc.body, tc.expected, results)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+//go:build ignore
+// +build ignore
+
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Reads the advice rules in forecast/advice.json and encodes them as a
+// string literal in forecast/advicerules.go
+func main() {
+	wd, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+	in, err := os.Open(filepath.Join(wd, "forecast", "advice.json"))
+	if err != nil {
+		panic(err)
+	}
+	defer in.Close()
+	out, err := os.Create(filepath.Join(wd, "forecast", "advicerules.go"))
+	if err != nil {
+		panic(err)
+	}
+	defer out.Close()
+
+	out.Write([]byte("// Code generated by forecast/generate.go from forecast/advice.json; DO NOT EDIT.\n\npackage forecast\n\n"))
+	out.Write([]byte("// defaultRulesJSON is generated from advice.json\nconst defaultRulesJSON = `"))
+	io.Copy(out, in)
+	out.Write([]byte("`\n"))
+}
@@ -0,0 +1,240 @@
+package forecast
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/genuinetools/weather/astro"
+	"github.com/mitchellh/colorstring"
+)
+
+const (
+	// LayoutGrid prints the daily forecast as a grid of columns.
+	LayoutGrid = "grid"
+	// LayoutProse prints the daily forecast as a paragraph per day.
+	LayoutProse = "prose"
+
+	// GridMinWidth is the narrowest terminal the grid layout is the
+	// default for.
+	GridMinWidth = 100
+
+	gridColumnWidth = 18
+	gridSeparator   = " │ "
+)
+
+// Layouts are the available layouts of the daily forecast.
+var Layouts = []string{LayoutGrid, LayoutProse}
+
+// smallIcons are five line tall icons for the grid layout.
+var smallIcons = map[string][]string{
+	"clearday": {
+		`    \   /    `,
+		`     .-.     `,
+		`  ― (   ) ―  `,
+		`     '-'     `,
+		`    /   \    `,
+	},
+	"clearnight": {
+		`     .--.    `,
+		`    (  (     `,
+		`    (   (    `,
+		`    (  (     `,
+		`     '--'    `,
+	},
+	"partlycloudyday": {
+		`   \  /      `,
+		` _ /"".-.    `,
+		`   \_(   ).  `,
+		`   /(___(__) `,
+		`             `,
+	},
+	"partlycloudynight": {
+		`   .-.       `,
+		`  (  .-.     `,
+		`   \(   ).   `,
+		`   (___(__)  `,
+		`             `,
+	},
+	"cloudy": {
+		`             `,
+		`     .--.    `,
+		`  .-(    ).  `,
+		` (___.__)__) `,
+		`             `,
+	},
+	"fog": {
+		`             `,
+		` _ - _ - _ - `,
+		`  _ - _ - _  `,
+		` _ - _ - _ - `,
+		`             `,
+	},
+	"rain": {
+		`     .-.     `,
+		`    (   ).   `,
+		`   (___(__)  `,
+		`    ‚'‚'‚'‚' `,
+		`    ‚'‚'‚'‚' `,
+	},
+	"sleet": {
+		`     .-.     `,
+		`    (   ).   `,
+		`   (___(__)  `,
+		`    ' * ' *  `,
+		`   * ' * '   `,
+	},
+	"snow": {
+		`     .-.     `,
+		`    (   ).   `,
+		`   (___(__)  `,
+		`    *  *  *  `,
+		`   *  *  *   `,
+	},
+	"thunderstorm": {
+		`     .-.     `,
+		`    (   ).   `,
+		`   (___(__)  `,
+		`   ‚'ϟ'‚ϟ‚'  `,
+		`   ‚'‚'ϟ'‚'  `,
+	},
+	"wind": {
+		`             `,
+		`  ~~~~~~~~   `,
+		`    ~~~~~~~~ `,
+		`  ~~~~~~~~   `,
+		`             `,
+	},
+	"unknown": {
+		`    .-.      `,
+		`     __)     `,
+		`    (        `,
+		`     '-'     `,
+		`      •      `,
+	},
+}
+
+// getSmallIcon returns the small icon for the grid layout.
+func getSmallIcon(iconStr string) []string {
+	// steralize the icon string name
+	iconStr = strings.Replace(strings.Replace(iconStr, "-", "", -1), "_", "", -1)
+
+	switch iconStr {
+	case "clear":
+		iconStr = "clearday"
+	case "clouds":
+		iconStr = "cloudy"
+	case "cloudsnight":
+		iconStr = "partlycloudynight"
+	case "haze", "hazenight":
+		iconStr = "fog"
+	case "tornado":
+		iconStr = "wind"
+	}
+
+	if icon, ok := smallIcons[iconStr]; ok {
+		return icon
+	}
+	return smallIcons["unknown"]
+}
+
+// printDailyGrid prints the daily forecast as a column per day, wrapped to
+// the width of the terminal.
+func printDailyGrid(w io.Writer, forecast Forecast, opts Options) error {
+	unitsFormat := forecast.Units()
+	tf := newTimeFormat(forecast, opts)
+
+	// Ignore the current day as it's printed before
+	if len(forecast.Daily.Data) < 2 {
+		return nil
+	}
+	data := forecast.Daily.Data[1:]
+	if opts.Days < len(data) {
+		data = data[:opts.Days]
+	}
+
+	separator := utf8.RuneCountInString(gridSeparator)
+	perRow := (opts.width() + separator) / (gridColumnWidth + separator)
+	if perRow < 1 {
+		perRow = 1
+	}
+
+	for start := 0; start < len(data); start += perRow {
+		end := start + perRow
+		if end > len(data) {
+			end = len(data)
+		}
+
+		// build the cells of each column, with the color to print them in
+		columns := make([][][2]string, 0, end-start)
+		for _, daily := range data[start:end] {
+			_, color := getIconDetails(daily.Icon)
+			cells := [][2]string{
+				{tf.shortDate(daily.Time), "magenta"},
+			}
+			if !opts.HideIcon {
+				for _, line := range getSmallIcon(daily.Icon) {
+					cells = append(cells, [2]string{line, color})
+				}
+			}
+			cells = append(cells,
+				[2]string{daily.Summary, ""},
+				[2]string{fmt.Sprintf("↑ %.0f%s  ↓ %.0f%s", daily.TemperatureMax, unitsFormat.Degrees, daily.TemperatureMin, unitsFormat.Degrees), "blue"},
+				[2]string{fmt.Sprintf("☂ %.0f%%", daily.PrecipProbability*100), "cyan"},
+				[2]string{fmt.Sprintf("≋ %.0f %s %s", daily.WindSpeed, unitsFormat.Speed, getBearingDetails(daily.WindBearing)), ""},
+			)
+			if a, ok := getAstronomy(forecast, daily); ok {
+				sun := "☀ " + tf.format(a.sunrise, tf.clock()) + "-" + tf.format(a.sunset, tf.clock())
+				switch {
+				case a.polar != "" && a.alwaysUp:
+					sun = "☀ all day"
+				case a.polar != "":
+					sun = "☀ none"
+				}
+				cells = append(cells, [2]string{sun + " " + astro.PhaseGlyph(a.moonPhase), "yellow"})
+			}
+			columns = append(columns, cells)
+		}
+
+		// the columns of days without the astronomy are a row shorter
+		rows := 0
+		for _, cells := range columns {
+			if len(cells) > rows {
+				rows = len(cells)
+			}
+		}
+
+		for i := 0; i < rows; i++ {
+			var line bytes.Buffer
+			for j, cells := range columns {
+				if j > 0 {
+					line.WriteString(gridSeparator)
+				}
+				var text, color string
+				if i < len(cells) {
+					text, color = truncate(cells[i][0], gridColumnWidth), cells[i][1]
+				}
+				padding := strings.Repeat(" ", gridColumnWidth-utf8.RuneCountInString(text))
+				if color != "" {
+					text = colorstring.Color("[" + color + "]" + text)
+				}
+				line.WriteString(text + padding)
+			}
+			fmt.Fprintln(w, strings.TrimRight(line.String(), " "))
+		}
+		fmt.Fprintln(w)
+	}
+
+	return nil
+}
+
+// truncate shortens s to at most n characters, ending with an ellipsis if
+// it was cut.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
@@ -0,0 +1,87 @@
+package forecast
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mitchellh/colorstring"
+)
+
+// historyDateWidth is the width of the dates in the history.
+const historyDateWidth = 12
+
+// precipTotal returns the total precipitation of the day from its average
+// intensity, and the unit it is in, e.g. "mm" for "mm/h".
+func precipTotal(day Weather, precipUnit string) (float64, string) {
+	return day.PrecipIntensity * 24, strings.SplitN(precipUnit, "/", 2)[0]
+}
+
+// PrintHistory pretty prints the weather on past days, from the forecasts
+// for each day in order: a row per day and the extremes and totals of a
+// range of days, or the hours of a single day.
+func PrintHistory(w io.Writer, days []Forecast, place string, opts Options) error {
+	if len(days) == 0 {
+		return nil
+	}
+	unitsFormat := days[0].Units()
+	tf := newTimeFormat(days[0], opts)
+	system := days[0].System()
+
+	dayOf := func(fc Forecast) (Weather, bool) {
+		if len(fc.Daily.Data) == 0 {
+			return Weather{}, false
+		}
+		return fc.Daily.Data[0], true
+	}
+
+	first, _ := dayOf(days[0])
+	last, _ := dayOf(days[len(days)-1])
+	when := tf.date(first.Time)
+	if len(days) > 1 {
+		when += " to " + tf.date(last.Time)
+	}
+	fmt.Fprintf(w, "Weather in %s on %s\n\n", colorstring.Color("[green]"+place), colorstring.Color("[magenta]"+when))
+
+	high, low, total := math.Inf(-1), math.Inf(1), 0.0
+	var highDay, lowDay Weather
+	totalUnit := ""
+	for _, fc := range days {
+		day, ok := dayOf(fc)
+		if !ok {
+			continue
+		}
+		precip, unit := precipTotal(day, system.Precipitation)
+		total, totalUnit = total+precip, unit
+		if day.TemperatureMax > high {
+			high, highDay = day.TemperatureMax, day
+		}
+		if day.TemperatureMin < low {
+			low, lowDay = day.TemperatureMin, day
+		}
+
+		date := tf.shortDate(day.Time)
+		fmt.Fprintf(w, "  %s%s %s  %s  %s  %s  %s\n",
+			date, strings.Repeat(" ", historyDateWidth-utf8.RuneCountInString(date)),
+			getGlyph(day.Icon),
+			colorstring.Color(fmt.Sprintf("[blue]↑ %4.0f%s ↓ %4.0f%s", day.TemperatureMax, unitsFormat.Degrees, day.TemperatureMin, unitsFormat.Degrees)),
+			colorstring.Color(fmt.Sprintf("[cyan]☂ %5.1f %-2s", precip, unit)),
+			fmt.Sprintf("≋ %3.0f %s", day.WindSpeed, unitsFormat.Speed),
+			day.Summary)
+	}
+	fmt.Fprintln(w)
+
+	if len(days) > 1 && !math.IsInf(high, 0) {
+		fmt.Fprintf(w, "  The highest temperature was %s on %s and the lowest %s on %s\n",
+			colorstring.Color(fmt.Sprintf("[bold]%.0f%s", high, unitsFormat.Degrees)), tf.date(highDay.Time),
+			colorstring.Color(fmt.Sprintf("[bold]%.0f%s", low, unitsFormat.Degrees)), tf.date(lowDay.Time))
+		fmt.Fprintf(w, "  The total precipitation was %s\n\n", colorstring.Color(fmt.Sprintf("[bold]%.1f %s", total, totalUnit)))
+		return nil
+	}
+
+	// show the hours of a single day
+	opts.Hours = len(days[0].Hourly.Data)
+	return printHourly(w, days[0], opts)
+}
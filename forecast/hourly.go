@@ -0,0 +1,92 @@
+package forecast
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/colorstring"
+)
+
+const (
+	// MaxHours is the most hours of hourly forecast that can be shown.
+	MaxHours = 48
+
+	hourlyLabelWidth  = 7
+	hourlyColumnWidth = 9
+)
+
+// printHourly prints a table of the hourly forecast with a column per hour,
+// wrapped to the width of the terminal.
+func printHourly(w io.Writer, forecast Forecast, opts Options) error {
+	unitsFormat := forecast.Units()
+	tf := newTimeFormat(forecast, opts)
+	degrees := func(f float64) string {
+		return fmt.Sprintf("%.0f%s", f, unitsFormat.Degrees)
+	}
+
+	hours := opts.Hours
+	if hours > MaxHours {
+		hours = MaxHours
+	}
+	if hours > len(forecast.Hourly.Data) {
+		hours = len(forecast.Hourly.Data)
+	}
+	if hours < 1 {
+		return nil
+	}
+
+	perRow := (opts.width() - hourlyLabelWidth) / hourlyColumnWidth
+	if perRow < 1 {
+		perRow = 1
+	}
+
+	now := time.Now().Unix()
+	for start := 0; start < hours; start += perRow {
+		end := start + perRow
+		if end > hours {
+			end = hours
+		}
+		data := forecast.Hourly.Data[start:end]
+
+		rows := []struct {
+			label string
+			value func(Weather) string
+		}{
+			{"", func(weather Weather) string { return tf.hour(weather.Time) }},
+			{"", func(weather Weather) string { return getGlyph(weather.Icon) }},
+			{"Temp", func(weather Weather) string { return degrees(weather.Temperature) }},
+			{"Feels", func(weather Weather) string { return degrees(weather.ApparentTemperature) }},
+			{"Rain", func(weather Weather) string { return fmt.Sprintf("%.0f%%", weather.PrecipProbability*100) }},
+			{"Wind", func(weather Weather) string { return fmt.Sprintf("%.0f %s", weather.WindSpeed, unitsFormat.Speed) }},
+			{"", func(weather Weather) string { return getBearingDetails(weather.WindBearing) }},
+		}
+
+		for _, row := range rows {
+			var line bytes.Buffer
+			fmt.Fprintf(&line, "%-*s", hourlyLabelWidth, row.label)
+			for _, weather := range data {
+				cell := fmt.Sprintf("%-*s", hourlyColumnWidth, row.value(weather))
+				// highlight the current hour
+				if weather.Time <= now && now < weather.Time+60*60 {
+					cell = colorstring.Color("[bold][cyan]" + cell)
+				}
+				line.WriteString(cell)
+			}
+			fmt.Fprintln(w, strings.TrimRight(line.String(), " "))
+		}
+		fmt.Fprintln(w)
+	}
+
+	return nil
+}
+
+// width returns the width to wrap the output to.
+func (opts Options) width() int {
+	if opts.Width > 0 {
+		return opts.Width
+	}
+	return 80
+}
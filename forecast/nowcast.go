@@ -0,0 +1,133 @@
+package forecast
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/genuinetools/weather/chart"
+	"github.com/genuinetools/weather/units"
+)
+
+// Precipitation intensities in inches per hour, as used by Dark Sky to
+// describe the precipitation.
+const (
+	intensityVeryLight = 0.002
+	intensityLight     = 0.017
+	intensityModerate  = 0.1
+	intensityHeavy     = 0.4
+)
+
+// intensityInches returns the precipitation intensity of the weather in
+// inches per hour, whatever the units of the forecast.
+func intensityInches(weather Weather, system units.System) float64 {
+	return units.PrecipRateIn(weather.PrecipIntensity, system.Precipitation).In(units.InchesPerHour)
+}
+
+// intensityName returns the plain language name for an intensity in
+// inches per hour.
+func intensityName(inches float64) string {
+	switch {
+	case inches >= intensityHeavy:
+		return "Heavy"
+	case inches >= intensityModerate:
+		return "Moderate"
+	case inches >= intensityLight:
+		return "Light"
+	}
+	return "Very light"
+}
+
+// Nowcast describes when the precipitation in the minute by minute
+// forecast starts or stops, e.g. "Rain stopping in 8 min" or
+// "Light rain starting in 25 min".
+func Nowcast(forecast Forecast) string {
+	data := forecast.Minutely.Data
+	if len(data) == 0 {
+		return ""
+	}
+
+	precipitating := func(weather Weather) bool {
+		return intensityInches(weather, forecast.System()) >= intensityVeryLight
+	}
+	minutes := func(weather Weather) int64 {
+		return (weather.Time - data[0].Time) / 60
+	}
+	precipType := func(weather Weather) string {
+		if weather.PrecipType == "" {
+			return "rain"
+		}
+		return weather.PrecipType
+	}
+
+	if precipitating(data[0]) {
+		name := strings.Title(precipType(data[0]))
+		for _, minutely := range data[1:] {
+			if !precipitating(minutely) {
+				return fmt.Sprintf("%s stopping in %d min", name, minutes(minutely))
+			}
+		}
+		return name + " continuing for the hour"
+	}
+
+	for i, minutely := range data {
+		if !precipitating(minutely) {
+			continue
+		}
+		// describe the precipitation by its heaviest until it stops
+		max := 0.0
+		for _, next := range data[i:] {
+			if !precipitating(next) {
+				break
+			}
+			max = math.Max(max, intensityInches(next, forecast.System()))
+		}
+		return fmt.Sprintf("%s %s starting in %d min", intensityName(max), precipType(minutely), minutes(minutely))
+	}
+
+	return "No precipitation for the hour"
+}
+
+// printNowcast prints a chart of the precipitation intensity for the next
+// hour and when the precipitation starts or stops.
+func printNowcast(w io.Writer, forecast Forecast, opts Options) error {
+	data := forecast.Minutely.Data
+	if len(data) == 0 {
+		fmt.Fprintln(w, "There is no minute by minute forecast for this location.")
+		fmt.Fprintln(w)
+		return nil
+	}
+
+	unitsFormat := forecast.Units()
+	width := opts.width() - chartLabelWidth - chartLabelMargin
+	if width < 10 {
+		width = 10
+	}
+
+	fmt.Fprintln(w, Nowcast(forecast))
+	if forecast.Minutely.Summary != "" {
+		fmt.Fprintln(w, forecast.Minutely.Summary)
+	}
+	fmt.Fprintln(w)
+
+	// scale to at least moderate precipitation so light rain looks light
+	max := units.PrecipRateIn(intensityModerate, units.InchesPerHour).In(forecast.System().Precipitation)
+	intensities := make([]float64, len(data))
+	labels := make([]string, len(data))
+	for i, minutely := range data {
+		intensities[i] = minutely.PrecipIntensity
+		max = math.Max(max, minutely.PrecipIntensity)
+		if m := (minutely.Time - data[0].Time) / 60; m%10 == 0 {
+			labels[i] = fmt.Sprintf("%dm", m)
+		}
+	}
+	labels[0] = "now"
+
+	fmt.Fprintf(w, "Precipitation intensity (%s)\n", unitsFormat.Precipitation)
+	rows := chart.Bars(intensities, max, "blue", width, chartHeight/2, opts.ASCII)
+	writeChartRows(w, rows, fmt.Sprintf("%.2g", max), "0", opts)
+	writeChartAxis(w, labels, width, false)
+
+	return nil
+}
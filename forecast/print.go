@@ -3,12 +3,15 @@ package forecast
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"math"
+	"os"
 	"strings"
-	"time"
 
+	"github.com/genuinetools/weather/comfort"
 	"github.com/genuinetools/weather/geocode"
 	"github.com/genuinetools/weather/icons"
+	"github.com/genuinetools/weather/units"
 	"github.com/mitchellh/colorstring"
 )
 
@@ -18,76 +21,34 @@ type UnitMeasures struct {
 	Speed         string
 	Length        string
 	Precipitation string
+	Pressure      string
 }
 
 var (
 	// UnitFormats describe each regions UnitMeasures.
-	UnitFormats = map[string]UnitMeasures{
-		"us": {
-			Degrees:       "°F",
-			Speed:         "mph",
-			Length:        "miles",
-			Precipitation: "in/hr",
-		},
-		"si": {
-			Degrees:       "°C",
-			Speed:         "m/s",
-			Length:        "kilometers",
-			Precipitation: "mm/h",
-		},
-		"ca": {
-			Degrees:       "°C",
-			Speed:         "km/h",
-			Length:        "kilometers",
-			Precipitation: "mm/h",
-		},
-		// deprecated, use "uk2" in stead
-		"uk": {
-			Degrees:       "°C",
-			Speed:         "mph",
-			Length:        "kilometers",
-			Precipitation: "mm/h",
-		},
-		"uk2": {
-			Degrees:       "°C",
-			Speed:         "mph",
-			Length:        "miles",
-			Precipitation: "mm/h",
-		},
-	}
+	UnitFormats = map[string]UnitMeasures{}
+
 	// Directions contain all the combinations of N,S,E,W
 	Directions = []string{
 		"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
 	}
 )
 
-func epochFormat(seconds int64) string {
-	epochTime := time.Unix(0, seconds*int64(time.Second))
-	return epochTime.Format("January 2 at 3:04pm MST")
-}
-
-func epochFormatDate(seconds int64) string {
-	epochTime := time.Unix(0, seconds*int64(time.Second))
-	return epochTime.Format("January 2 (Monday)")
-}
-
-func epochFormatTime(seconds int64) string {
-	epochTime := time.Unix(0, seconds*int64(time.Second))
-	return epochTime.Format("3:04pm MST")
-}
-
-func epochFormatHour(seconds int64) string {
-	epochTime := time.Unix(0, seconds*int64(time.Second))
-	s := epochTime.Format("3pm")
-	s = s[:len(s)-1]
-	if len(s) == 2 {
-		s += " "
+func init() {
+	for name, system := range units.Systems {
+		UnitFormats[name] = unitMeasures(system)
 	}
-	return s
 }
 
 func getIcon(iconStr string) (icon string, err error) {
-	color := "blue"
+	icon, color := getIconDetails(iconStr)
+	return colorstring.Color("[" + color + "]" + icon), nil
+}
+
+// getIconDetails returns the ascii art and the color to print it in for
+// the icon.
+func getIconDetails(iconStr string) (icon, color string) {
+	color = "blue"
 	// steralize the icon string name
 	iconStr = strings.Replace(strings.Replace(iconStr, "-", "", -1), "_", "", -1)
 
@@ -138,7 +99,35 @@ func getIcon(iconStr string) (icon string, err error) {
 		icon = icons.Wind
 	}
 
-	return colorstring.Color("[" + color + "]" + icon), nil
+	return icon, color
+}
+
+// getGlyph returns a single character symbol for the icon.
+func getGlyph(iconStr string) string {
+	// steralize the icon string name
+	iconStr = strings.Replace(strings.Replace(iconStr, "-", "", -1), "_", "", -1)
+
+	switch iconStr {
+	case "clear", "clearday":
+		return "☀"
+	case "clearnight":
+		return "☾"
+	case "clouds", "cloudy", "cloudsnight", "partlycloudyday", "partlycloudynight":
+		return "☁"
+	case "fog", "haze", "hazenight":
+		return "≡"
+	case "rain":
+		return "☂"
+	case "sleet", "snow":
+		return "❄"
+	case "thunderstorm":
+		return "ϟ"
+	case "tornado":
+		return "◎"
+	case "wind":
+		return "≋"
+	}
+	return "?"
 }
 
 func getBearingDetails(degrees float64) string {
@@ -146,118 +135,161 @@ func getBearingDetails(degrees float64) string {
 	return Directions[index]
 }
 
-func printCommon(weather Weather, unitsFormat UnitMeasures) error {
+func printCommon(w io.Writer, weather Weather, system units.System, thresholds comfort.Thresholds) error {
+	unitsFormat := unitMeasures(system)
+	metrics, hasMetrics := comfortMetrics(weather, system, thresholds)
+
 	if weather.DewPoint > 0 {
 		dewPoint := colorstring.Color(fmt.Sprintf("[bold]%.2f%s", weather.DewPoint, unitsFormat.Degrees))
 
-		if weather.DewPoint > 60 {
-			fmt.Printf("  Ugh! The dew point is %s\n", dewPoint)
+		if metrics.Muggy {
+			fmt.Fprintf(w, "  Ugh! The dew point is %s\n", dewPoint)
 		} else {
-			fmt.Printf("  The dew point is %s\n", dewPoint)
+			fmt.Fprintf(w, "  The dew point is %s\n", dewPoint)
 		}
 	}
 
 	if weather.Humidity > 0 {
 		humidity := colorstring.Color(fmt.Sprintf("[bold]%.2f%s", weather.Humidity*100, "%"))
 
-		if weather.Humidity > 0.20 {
-			fmt.Printf("  Ick! The humidity is %s\n", humidity)
+		if metrics.Humid {
+			fmt.Fprintf(w, "  Ick! The humidity is %s\n", humidity)
 		} else {
-			fmt.Printf("  The humidity is %s\n", humidity)
+			fmt.Fprintf(w, "  The humidity is %s\n", humidity)
 		}
 	}
 
+	if hasMetrics {
+		printComfort(w, weather, metrics, system)
+	}
+
 	if weather.PrecipIntensity > 0 {
 		precInt := colorstring.Color(fmt.Sprintf("[bold]%.1f %s", weather.PrecipIntensity, unitsFormat.Precipitation))
-		fmt.Printf("  The precipitation intensity of %s is %s\n", colorstring.Color("[bold]"+weather.PrecipType), precInt)
+		fmt.Fprintf(w, "  The precipitation intensity of %s is %s\n", colorstring.Color("[bold]"+weather.PrecipType), precInt)
 	}
 
 	if weather.PrecipProbability > 0 {
 		prec := colorstring.Color(fmt.Sprintf("[bold]%.0f%s", weather.PrecipProbability*100, "%"))
-		fmt.Printf("  The precipitation probability is %s\n", prec)
+		fmt.Fprintf(w, "  The precipitation probability is %s\n", prec)
 	}
 
 	if weather.NearestStormDistance > 0 {
 		dist := colorstring.Color(fmt.Sprintf("[bold]%.1f %s %v", weather.NearestStormDistance, unitsFormat.Length, getBearingDetails(weather.NearestStormBearing)))
-		fmt.Printf("  The nearest storm is %s away\n", dist)
+		fmt.Fprintf(w, "  The nearest storm is %s away\n", dist)
 	}
 
 	if weather.WindSpeed > 0 {
 		wind := colorstring.Color(fmt.Sprintf("[bold]%.2f %s %v", weather.WindSpeed, unitsFormat.Speed, getBearingDetails(weather.WindBearing)))
-		fmt.Printf("  The wind speed is %s\n", wind)
+		fmt.Fprintf(w, "  The wind speed is %s\n", wind)
 	}
 
 	if weather.CloudCover > 0 {
 
 		cloudCover := colorstring.Color(fmt.Sprintf("[bold]%.2f%s", weather.CloudCover*100, "%"))
 
-		fmt.Printf("  The cloud coverage is %s\n", cloudCover)
+		fmt.Fprintf(w, "  The cloud coverage is %s\n", cloudCover)
 	}
 
-	if (unitsFormat.Length == "kilometers" && kmToMile(weather.Visibility) < 10) || weather.Visibility < 10 {
-		visibility := colorstring.Color(fmt.Sprintf("[bold]%.1f %s", weather.Visibility, unitsFormat.Length))
-		fmt.Printf("  The visibility is %s\n", visibility)
+	// the visibility is capped at 10 miles, so only show it when it is less
+	visibility := units.DistanceIn(weather.Visibility, system.Distance)
+	if weather.Visibility > 0 && visibility < units.DistanceIn(10, units.Miles) {
+		fmt.Fprintf(w, "  The visibility is %s\n", colorstring.Color(fmt.Sprintf("[bold]%.1f %s", weather.Visibility, unitsFormat.Length)))
 	}
 
 	if weather.Pressure > 0 {
-		pressure := colorstring.Color(fmt.Sprintf("[bold]%.1f %s", weather.Pressure, "mbar"))
-		fmt.Printf("  The pressure is %s\n\n", pressure)
+		pressure := colorstring.Color(fmt.Sprintf("[bold]%.1f %s", weather.Pressure, unitsFormat.Pressure))
+		fmt.Fprintf(w, "  The pressure is %s\n\n", pressure)
 	}
 
 	return nil
 }
 
-func kmToMile(km float64) float64 {
-	return km * 0.621371
-}
-
 // PrintCurrent pretty prints the current forecast data.
 func PrintCurrent(forecast Forecast, geolocation geocode.Geocode, ignoreAlerts bool, hideIcon bool) error {
-	unitsFormat := UnitFormats[forecast.Flags.Units]
+	return printCurrent(os.Stdout, forecast, geolocation, Options{IgnoreAlerts: ignoreAlerts, HideIcon: hideIcon})
+}
 
-	if !hideIcon {
+// printCurrent pretty prints the current forecast data to w.
+func printCurrent(w io.Writer, forecast Forecast, geolocation geocode.Geocode, opts Options) error {
+	return printWeather(w, forecast, geolocation, opts, nil)
+}
+
+// printWeather pretty prints the current weather of the forecast, or the
+// weather at the moment if it is set.
+func printWeather(w io.Writer, forecast Forecast, geolocation geocode.Geocode, opts Options, moment *Moment) error {
+	unitsFormat := forecast.Units()
+	tf := newTimeFormat(forecast, opts)
+
+	if !opts.HideIcon {
 		icon, err := getIcon(forecast.Currently.Icon)
 		if err != nil {
 			return err
 		}
 
-		fmt.Println(icon)
+		fmt.Fprintln(w, icon)
 	}
 
 	location := colorstring.Color(fmt.Sprintf("[green]%s in %s", geolocation.City, geolocation.Region))
-	fmt.Printf("\nCurrent weather is %s in %s for %s\n", colorstring.Color("[cyan]"+forecast.Currently.Summary), location, colorstring.Color("[cyan]"+epochFormat(forecast.Currently.Time)))
+	switch {
+	case moment == nil:
+		fmt.Fprintf(w, "\nCurrent weather is %s in %s for %s\n", colorstring.Color("[cyan]"+forecast.Currently.Summary), location, colorstring.Color("[cyan]"+tf.dateTime(forecast.Currently.Time)))
+	case moment.Daily:
+		fmt.Fprintf(w, "\nForecast for %s in %s: %s\n", colorstring.Color("[cyan]"+tf.date(moment.Time.Unix())), location, colorstring.Color("[cyan]"+forecast.Currently.Summary))
+	default:
+		fmt.Fprintf(w, "\nForecast weather is %s in %s for %s\n", colorstring.Color("[cyan]"+forecast.Currently.Summary), location, colorstring.Color("[cyan]"+tf.dateTime(forecast.Currently.Time)))
+	}
+	if moment != nil && moment.Note != "" {
+		fmt.Fprintln(w, moment.Note)
+	}
 
-	temp := colorstring.Color(fmt.Sprintf("[magenta]%v%s", forecast.Currently.Temperature, unitsFormat.Degrees))
-	feelslike := colorstring.Color(fmt.Sprintf("[magenta]%v%s", forecast.Currently.ApparentTemperature, unitsFormat.Degrees))
-	if temp == feelslike {
-		fmt.Printf("The temperature is %s\n\n", temp)
+	if moment != nil && moment.Daily {
+		tempMax := colorstring.Color(fmt.Sprintf("[magenta]%v%s", forecast.Currently.TemperatureMax, unitsFormat.Degrees))
+		tempMin := colorstring.Color(fmt.Sprintf("[magenta]%v%s", forecast.Currently.TemperatureMin, unitsFormat.Degrees))
+		fmt.Fprintf(w, "The high is %s and the low is %s\n\n", tempMax, tempMin)
 	} else {
-		fmt.Printf("The temperature is %s, but it feels like %s\n\n", temp, feelslike)
+		temp := colorstring.Color(fmt.Sprintf("[magenta]%v%s", forecast.Currently.Temperature, unitsFormat.Degrees))
+		feelslike := colorstring.Color(fmt.Sprintf("[magenta]%v%s", forecast.Currently.ApparentTemperature, unitsFormat.Degrees))
+		if temp == feelslike {
+			fmt.Fprintf(w, "The temperature is %s\n\n", temp)
+		} else {
+			fmt.Fprintf(w, "The temperature is %s, but it feels like %s\n\n", temp, feelslike)
+		}
 	}
 
-	if !ignoreAlerts {
+	if !opts.IgnoreAlerts {
 		for _, alert := range forecast.Alerts {
 			if alert.Title != "" {
-				fmt.Println(colorstring.Color("[red]" + alert.Title))
+				fmt.Fprintln(w, colorstring.Color("[red]"+alert.Title))
 			}
 			if alert.Description != "" {
-				fmt.Print(colorstring.Color("[red]" + alert.Description))
+				fmt.Fprint(w, colorstring.Color("[red]"+alert.Description))
 			}
-			fmt.Println("\t\t\t" + colorstring.Color("[red]Created: "+epochFormat(alert.Time)))
-			fmt.Println("\t\t\t" + colorstring.Color("[red]Expires: "+epochFormat(alert.Expires)) + "\n")
+			fmt.Fprintln(w, "\t\t\t"+colorstring.Color("[red]Created: "+tf.dateTime(alert.Time)))
+			fmt.Fprintln(w, "\t\t\t"+colorstring.Color("[red]Expires: "+tf.dateTime(alert.Expires))+"\n")
 		}
 	}
 
-	if err := printCommon(forecast.Currently, unitsFormat); err != nil {
+	if err := printCommon(w, forecast.Currently, forecast.System(), opts.thresholds()); err != nil {
 		return err
 	}
 
+	today := Weather{Time: forecast.Currently.Time}
+	if len(forecast.Daily.Data) > 0 {
+		today = forecast.Daily.Data[0]
+	}
+	printAstronomy(w, forecast, today, tf)
+
+	// the summaries and advice are for the hours from now
+	if moment != nil {
+		return nil
+	}
+
 	if forecast.Hourly.Summary != "" {
-		fmt.Printf("%s\n\n", forecast.Hourly.Summary)
+		fmt.Fprintf(w, "%s\n\n", forecast.Hourly.Summary)
 
 		var ticks = []rune(" ▁▂▃▄▅▆▇█")
 		rainForecast, showRain := &bytes.Buffer{}, false
-		for i := 0; i < 16; i++ {
+		for i := 0; i < 16 && i < len(forecast.Hourly.Data); i++ {
 			p := forecast.Hourly.Data[i].PrecipProbability
 			t := int(p*float64(len(ticks)-2)) + 1
 			if p == 0 {
@@ -270,39 +302,54 @@ func PrintCurrent(forecast Forecast, geolocation geocode.Geocode, ignoreAlerts b
 			rainForecast.WriteRune(' ')
 		}
 		if showRain {
-			fmt.Printf("Rain chance: %s\n", rainForecast)
-			fmt.Printf("             ")
-			for i := 0; i < 4; i++ {
-				fmt.Printf("%s         ", epochFormatHour(forecast.Hourly.Data[i*4].Time))
+			fmt.Fprintf(w, "Rain chance: %s\n", rainForecast)
+			fmt.Fprintf(w, "             ")
+			for i := 0; i < 4 && i*4 < len(forecast.Hourly.Data); i++ {
+				fmt.Fprintf(w, "%s         ", tf.sparklineHour(forecast.Hourly.Data[i*4].Time))
 			}
-			fmt.Printf("\n\n")
+			fmt.Fprintf(w, "\n\n")
 		}
 	}
 
+	printAdvice(w, forecast, opts, tf)
+
 	return nil
 }
 
 // PrintDaily pretty prints the daily forecast data.
 func PrintDaily(forecast Forecast, days int) error {
-	unitsFormat := UnitFormats[forecast.Flags.Units]
+	return printDaily(os.Stdout, forecast, Options{Days: days})
+}
+
+// printDaily pretty prints the daily forecast data to w.
+func printDaily(w io.Writer, forecast Forecast, opts Options) error {
+	unitsFormat := forecast.Units()
+	tf := newTimeFormat(forecast, opts)
 
 	// Ignore the current day as it's printed before
+	if len(forecast.Daily.Data) < 2 {
+		return nil
+	}
 	for index, daily := range forecast.Daily.Data[1:] {
 		// only do the amount of days they request
-		if index == days {
+		if index == opts.Days {
 			break
 		}
 
-		fmt.Println(colorstring.Color("[magenta]" + epochFormatDate(daily.Time)))
+		fmt.Fprintln(w, colorstring.Color("[magenta]"+tf.date(daily.Time)))
 
 		tempMax := colorstring.Color(fmt.Sprintf("[blue]%v%s", daily.TemperatureMax, unitsFormat.Degrees))
 		tempMin := colorstring.Color(fmt.Sprintf("[blue]%v%s", daily.TemperatureMin, unitsFormat.Degrees))
 		feelsLikeMax := colorstring.Color(fmt.Sprintf("[cyan]%v%s", daily.ApparentTemperatureMax, unitsFormat.Degrees))
 		feelsLikeMin := colorstring.Color(fmt.Sprintf("[cyan]%v%s", daily.ApparentTemperatureMin, unitsFormat.Degrees))
-		fmt.Printf("The temperature high is %s, feels like %s around %s,\n", tempMax, feelsLikeMax, epochFormatTime(daily.TemperatureMaxTime))
-		fmt.Printf("and low is %s, feels like %s around %s\n\n", tempMin, feelsLikeMin, epochFormatTime(daily.TemperatureMinTime))
+		fmt.Fprintf(w, "The temperature high is %s, feels like %s around %s,\n", tempMax, feelsLikeMax, tf.time(daily.TemperatureMaxTime))
+		fmt.Fprintf(w, "and low is %s, feels like %s around %s\n\n", tempMin, feelsLikeMin, tf.time(daily.TemperatureMinTime))
+
+		if err := printCommon(w, daily, forecast.System(), opts.thresholds()); err != nil {
+			return err
+		}
 
-		printCommon(daily, unitsFormat)
+		printAstronomy(w, forecast, daily, tf)
 	}
 
 	return nil
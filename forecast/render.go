@@ -0,0 +1,130 @@
+package forecast
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/genuinetools/weather/comfort"
+	"github.com/genuinetools/weather/geocode"
+)
+
+// Options control what a Renderer outputs.
+type Options struct {
+	// IgnoreAlerts hides the weather alerts.
+	IgnoreAlerts bool
+	// HideIcon hides the weather icon.
+	HideIcon bool
+	// Days is the number of days of the daily forecast to output.
+	Days int
+	// Hours is the number of hours of the hourly forecast to output.
+	Hours int
+	// Width is the width of the terminal to fit the output to.
+	Width int
+	// Chart outputs charts of the hourly and daily forecast.
+	Chart bool
+	// ASCII draws charts with plain ascii characters.
+	ASCII bool
+	// Nowcast outputs the minute by minute precipitation for the next
+	// hour.
+	Nowcast bool
+	// Locale is the name of the locale to write dates in, see Locales.
+	Locale string
+	// Clock is 12 or 24 to use a 12 or 24-hour clock, or zero to use the
+	// locale's.
+	Clock int
+	// LocalTime also shows times in the local time zone when it differs
+	// from the forecast location's.
+	LocalTime bool
+	// Layout is the layout of the daily forecast, LayoutGrid or
+	// LayoutProse.
+	Layout string
+	// Comfort are the thresholds of the comfort metrics, the defaults if
+	// they are not set.
+	Comfort comfort.Thresholds
+	// Rules are the rules of what to wear and bring, DefaultRules if they
+	// are not set, and Window is when to advise for.
+	Rules  []Rule
+	Window Window
+}
+
+// Renderer writes the forecast for a location in a specific format.
+type Renderer interface {
+	Render(w io.Writer, forecast Forecast, geolocation geocode.Geocode, opts Options) error
+}
+
+// Renderers holds the available renderers by the name of their format.
+var Renderers = map[string]Renderer{
+	"text":   TextRenderer{},
+	"json":   JSONRenderer{},
+	"i3bar":  I3barRenderer{},
+	"waybar": WaybarRenderer{},
+	"tmux":   TmuxRenderer{},
+}
+
+// GetRenderer returns the renderer for the format.
+func GetRenderer(format string) (Renderer, error) {
+	r, ok := Renderers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q, expected one of: %s", format, strings.Join(RendererNames(), ", "))
+	}
+	return r, nil
+}
+
+// RendererNames returns the names of the available formats in sorted order.
+func RendererNames() []string {
+	names := make([]string, 0, len(Renderers))
+	for name := range Renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TextRenderer pretty prints the forecast for a terminal.
+type TextRenderer struct{}
+
+// Render pretty prints the current and daily forecast data.
+func (TextRenderer) Render(w io.Writer, forecast Forecast, geolocation geocode.Geocode, opts Options) error {
+	if err := printCurrent(w, forecast, geolocation, opts); err != nil {
+		return err
+	}
+
+	if opts.Nowcast {
+		if err := printNowcast(w, forecast, opts); err != nil {
+			return err
+		}
+	}
+
+	if opts.Hours > 0 {
+		if err := printHourly(w, forecast, opts); err != nil {
+			return err
+		}
+	}
+
+	if opts.Chart {
+		if err := printCharts(w, forecast, opts); err != nil {
+			return err
+		}
+	}
+
+	if opts.Days > 0 {
+		if opts.Layout == LayoutGrid {
+			return printDailyGrid(w, forecast, opts)
+		}
+		return printDaily(w, forecast, opts)
+	}
+
+	return nil
+}
+
+// JSONRenderer writes the raw forecast data as JSON.
+type JSONRenderer struct{}
+
+// Render writes the forecast as a single line of JSON.
+func (JSONRenderer) Render(w io.Writer, forecast Forecast, geolocation geocode.Geocode, opts Options) error {
+	forecast = forecast.WithComfort(opts.thresholds()).WithAdvice(opts.Rules, opts.Window)
+	return json.NewEncoder(w).Encode(&forecast)
+}
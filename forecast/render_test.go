@@ -0,0 +1,319 @@
+package forecast
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/genuinetools/weather/geocode"
+	"github.com/genuinetools/weather/icons"
+)
+
+// ansiRegex matches the color escape codes of the text output.
+var ansiRegex = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// testLocation is the location of testForecast.
+var testLocation = geocode.Geocode{City: "New York", Region: "NY", Latitude: 40.71, Longitude: -74.01}
+
+// testForecast returns a fixed forecast for New York, in si units, from
+// 9am on Friday March 1 2024 local time.
+func testForecast() Forecast {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		panic(err)
+	}
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, loc)
+
+	fc := Forecast{
+		Latitude:  testLocation.Latitude,
+		Longitude: testLocation.Longitude,
+		Timezone:  "America/New_York",
+		Offset:    -5,
+		Flags:     Flags{Units: "si"},
+		Currently: Weather{
+			Time:                now.Unix(),
+			Summary:             "Light Rain",
+			Icon:                "rain",
+			Temperature:         8,
+			ApparentTemperature: 5,
+			Humidity:            0.82,
+			DewPoint:            5,
+			WindSpeed:           6,
+			WindBearing:         225,
+			Pressure:            1012,
+			Visibility:          9,
+			PrecipProbability:   0.8,
+			PrecipIntensity:     1.2,
+			PrecipType:          "rain",
+			UVIndex:             1,
+		},
+		Alerts: []Alert{{
+			Title:       "Flood Watch",
+			Description: "Heavy rain may cause flooding.\n",
+			Time:        now.Unix(),
+			Expires:     now.Add(12 * time.Hour).Unix(),
+		}},
+	}
+
+	fc.Minutely.Summary = "Light rain for the hour."
+	for i := 0; i < 61; i++ {
+		fc.Minutely.Data = append(fc.Minutely.Data, Weather{
+			Time:              now.Add(time.Duration(i) * time.Minute).Unix(),
+			PrecipIntensity:   1.2,
+			PrecipProbability: 0.8,
+			PrecipType:        "rain",
+		})
+	}
+
+	fc.Hourly.Summary = "Rain until the afternoon."
+	for i := 0; i < 48; i++ {
+		hour := Weather{
+			Time:                now.Add(time.Duration(i) * time.Hour).Unix(),
+			Summary:             "Light Rain",
+			Icon:                "rain",
+			Temperature:         8 + float64(i%12),
+			ApparentTemperature: 5 + float64(i%12),
+			Humidity:            0.8,
+			WindSpeed:           6,
+			WindBearing:         225,
+			PrecipProbability:   0.8,
+			PrecipIntensity:     1.2,
+			PrecipType:          "rain",
+		}
+		if i >= 6 {
+			hour.Summary = "Cloudy"
+			hour.Icon = "cloudy"
+			hour.PrecipProbability = 0.1
+			hour.PrecipIntensity = 0
+		}
+		fc.Hourly.Data = append(fc.Hourly.Data, hour)
+	}
+
+	fc.Daily.Summary = "Rain today, dry over the weekend."
+	for i := 0; i < 7; i++ {
+		day := time.Date(2024, time.March, 1+i, 0, 0, 0, 0, loc)
+		fc.Daily.Data = append(fc.Daily.Data, Weather{
+			Time:                       day.Unix(),
+			Summary:                    "Partly cloudy throughout the day.",
+			Icon:                       "partly-cloudy-day",
+			TemperatureMax:             12 + float64(i),
+			TemperatureMin:             3 + float64(i),
+			TemperatureMaxTime:         day.Add(15 * time.Hour).Unix(),
+			TemperatureMinTime:         day.Add(6 * time.Hour).Unix(),
+			ApparentTemperatureMax:     10 + float64(i),
+			ApparentTemperatureMin:     1 + float64(i),
+			ApparentTemperatureMaxTime: day.Add(15 * time.Hour).Unix(),
+			ApparentTemperatureMinTime: day.Add(6 * time.Hour).Unix(),
+			PrecipProbability:          0.2,
+			WindSpeed:                  5,
+			WindBearing:                270,
+			Humidity:                   0.7,
+			SunriseTime:                day.Add(6*time.Hour + 30*time.Minute).Unix(),
+			SunsetTime:                 day.Add(17*time.Hour + 50*time.Minute).Unix(),
+			MoonPhase:                  0.7,
+		})
+	}
+	fc.Daily.Data[0].Summary = "Rain in the morning."
+	fc.Daily.Data[0].Icon = "rain"
+	fc.Daily.Data[0].PrecipProbability = 0.9
+
+	return fc
+}
+
+func TestRenderers(t *testing.T) {
+	testCases := []struct {
+		format string
+		// template is the text of a template renderer, used in place of
+		// the format
+		template string
+		opts     Options
+		// contains are expected in the output with the colors removed
+		contains []string
+		// json is true if the output is a line of JSON
+		json bool
+	}{
+		{
+			format: "text",
+			opts:   Options{HideIcon: true},
+			contains: []string{
+				"Current weather is Light Rain in New York in NY for",
+				"The temperature is 8°C, but it feels like 5°C",
+				"Flood Watch",
+				"Heavy rain may cause flooding.",
+			},
+		},
+		{
+			format:   "text",
+			opts:     Options{HideIcon: true, IgnoreAlerts: true},
+			contains: []string{"The temperature is 8°C"},
+		},
+		{
+			format:   "text",
+			opts:     Options{HideIcon: true, Hours: 6, Width: 120},
+			contains: []string{"Temp", "Feels", "Rain", "Wind", "80%", "6 m/s", "SW"},
+		},
+		{
+			format:   "text",
+			opts:     Options{HideIcon: true, Nowcast: true},
+			contains: []string{"Light rain for the hour."},
+		},
+		{
+			format:   "text",
+			opts:     Options{HideIcon: true, Chart: true},
+			contains: []string{"Temperature"},
+		},
+		{
+			format:   "text",
+			opts:     Options{HideIcon: true, Days: 3, Layout: LayoutProse},
+			contains: []string{"March 2 (Saturday)", "The temperature high is 13°C, feels like 11°C around 3:00pm EST", "March 4 (Monday)"},
+		},
+		{
+			format:   "text",
+			opts:     Options{HideIcon: true, Days: 3, Layout: LayoutGrid, Width: 120},
+			contains: []string{"Sat Mar 2", "↑ 13°C  ↓ 4°C", "≋ 5 m/s W", "Mon Mar 4"},
+		},
+		{
+			format:   "json",
+			json:     true,
+			contains: []string{`"summary":"Light Rain"`, `"timezone":"America/New_York"`, `"comfort":{`},
+		},
+		{
+			format:   "i3bar",
+			json:     true,
+			contains: []string{`"name":"weather"`, `"full_text":"`, `8°C Light Rain ⚠ 1 alert"`, `"color":"#dc322f"`, `"urgent":true`},
+		},
+		{
+			format:   "i3bar",
+			opts:     Options{IgnoreAlerts: true},
+			json:     true,
+			contains: []string{`8°C Light Rain"`, `"color":"#268bd2"`},
+		},
+		{
+			format:   "waybar",
+			json:     true,
+			contains: []string{`"class":"alert"`, `"alt":"rain"`, `"percentage":80`, `Light Rain in New York\nFeels like 5°C\nHumidity 82%\nWind 6 m/s SW`, `⚠ Flood Watch`},
+		},
+		{
+			format:   "tmux",
+			contains: []string{"#[fg=red]", "8°C Light Rain ⚠ 1 alert#[default]\n"},
+		},
+		{
+			format:   "tmux",
+			opts:     Options{IgnoreAlerts: true},
+			contains: []string{"#[fg=blue]", "8°C Light Rain#[default]\n"},
+		},
+		{
+			format:   "template",
+			template: "{{.Currently.Temperature}}{{.Units.Degrees}} {{.Currently.Summary}} in {{.Geocode.City}}",
+			contains: []string{"8°C Light Rain in New York\n"},
+		},
+		{
+			format:   "template",
+			template: "{{glyph .Currently.Icon}} {{bearing .Currently.WindBearing}} {{percent .Currently.PrecipProbability}}\n",
+			contains: []string{"☂ SW 80%\n"},
+		},
+		{
+			format:   "template",
+			template: "{{icon .Currently.Icon}}",
+			contains: []string{icons.Rain},
+		},
+		{
+			// the temperatures of the first 12 hours rise from 8°C to 19°C
+			format:   "template",
+			template: `{{sparkline (slice .Hourly.Data 0 12) "temperature"}}|{{sparkline .Hourly.Data "nothing"}}|`,
+			contains: []string{"▁▁▂▂▃▄▄▅▆▆▇█||"},
+		},
+		{
+			// the times are in the zone of New York, not the local one
+			format:   "template",
+			template: `{{time .Currently.Time "Mon 15:04 MST"}} {{time (index .Daily.Data 0).SunsetTime "15:04"}}`,
+			contains: []string{"Fri 09:00 EST 17:50"},
+		},
+		{
+			format:   "template",
+			template: "{{round .Currently.Humidity 1}} {{round 7.5}} {{round .Currently.PrecipIntensity}}",
+			contains: []string{"0.8 8 1"},
+		},
+	}
+
+	// every renderer is tested
+	tested := map[string]bool{}
+	for _, tc := range testCases {
+		tested[tc.format] = true
+	}
+	for _, name := range RendererNames() {
+		if !tested[name] {
+			t.Errorf("the %s renderer is not tested", name)
+		}
+	}
+
+	for _, tc := range testCases {
+		var r Renderer
+		var err error
+		if tc.template != "" {
+			r, err = NewTemplateRenderer(tc.template)
+		} else {
+			r, err = GetRenderer(tc.format)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if err := r.Render(&buf, testForecast(), testLocation, tc.opts); err != nil {
+			t.Errorf("%s %+v: %v", tc.format, tc.opts, err)
+			continue
+		}
+		out := ansiRegex.ReplaceAllString(buf.String(), "")
+
+		if tc.json {
+			if strings.Count(out, "\n") != 1 || !json.Valid([]byte(out)) {
+				t.Errorf("%s %+v: expected a single line of JSON, got %q", tc.format, tc.opts, out)
+			}
+		}
+		if tc.opts.IgnoreAlerts && strings.Contains(out, "Flood Watch") {
+			t.Errorf("%s %+v: expected the alerts to be hidden, got %q", tc.format, tc.opts, out)
+		}
+		for _, s := range tc.contains {
+			if !strings.Contains(out, s) {
+				t.Errorf("%s %+v: expected the output to contain %q, got:\n%s", tc.format, tc.opts, s, out)
+			}
+		}
+	}
+}
+
+// Without coordinates the astronomy of a day comes only from the
+// provider, so the columns of the days without it are a row shorter.
+func TestGridMissingAstronomy(t *testing.T) {
+	for _, missing := range [][]int{{2, 3}, {1}} {
+		fc := testForecast()
+		fc.Latitude, fc.Longitude = 0, 0
+		for _, i := range missing {
+			fc.Daily.Data[i].SunriseTime, fc.Daily.Data[i].SunsetTime = 0, 0
+		}
+
+		r, err := GetRenderer("text")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := r.Render(&buf, fc, testLocation, Options{HideIcon: true, IgnoreAlerts: true, Days: 3, Layout: LayoutGrid, Width: 120}); err != nil {
+			t.Fatalf("days %v without astronomy: %v", missing, err)
+		}
+		out := ansiRegex.ReplaceAllString(buf.String(), "")
+
+		// the days with the astronomy still show it
+		if got := strings.Count(out, "☀ 6:30am-5:50pm"); got != 3-len(missing) {
+			t.Errorf("days %v without astronomy: expected the sun times of %d days, got %d:\n%s", missing, 3-len(missing), got, out)
+		}
+	}
+}
+
+func TestGetRenderer(t *testing.T) {
+	if _, err := GetRenderer("xml"); err == nil || !strings.Contains(err.Error(), "i3bar, json, text, tmux, waybar") {
+		t.Errorf("expected an error listing the formats, got %v", err)
+	}
+}
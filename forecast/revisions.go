@@ -0,0 +1,263 @@
+package forecast
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/genuinetools/weather/units"
+	"github.com/mitchellh/colorstring"
+)
+
+// RevisionThresholds describe how big a revision to the hourly or daily
+// forecast has to be before it is reported, in the canonical units.
+type RevisionThresholds struct {
+	// Temperature is the revision of a high, low or hourly temperature in
+	// °C.
+	Temperature float64 `json:"temperature"`
+	// PrecipProbability is the revision of the chance of precipitation
+	// (0-1).
+	PrecipProbability float64 `json:"precipProbability"`
+	// WindSpeed is the revision of the wind speed of a day in m/s.
+	WindSpeed float64 `json:"windSpeed"`
+}
+
+// DefaultRevisionThresholds are the revision thresholds used if none are
+// configured.
+var DefaultRevisionThresholds = RevisionThresholds{
+	Temperature:       3,
+	PrecipProbability: 0.3,
+	WindSpeed:         5,
+}
+
+// Revision is a significant revision of the forecast for an hour, a run of
+// hours or a day.
+type Revision struct {
+	// Time is the start of the hour or day revised, and Until the end of
+	// the run of hours, or zero for a day.
+	Time  int64 `json:"time"`
+	Until int64 `json:"until,omitempty"`
+	// Field is the field of the data point revised, e.g. temperatureMax,
+	// and From and To its value before and after, in the units of the
+	// forecast.
+	Field string  `json:"field"`
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	// Message describes the revision, e.g. "Saturday's rain chance rose
+	// from 20% to 70%".
+	Message string `json:"message"`
+}
+
+// Revisions returns the significant revisions of the daily and hourly
+// forecast since the previous forecast for the same location, for the
+// hours and days still to come.
+func Revisions(previous, current Forecast, t RevisionThresholds, opts Options) []Revision {
+	system := current.System()
+	previous = previous.Convert(system)
+
+	// the thresholds are differences, so only scaled to the units
+	degrees := t.Temperature * (units.TemperatureIn(1, units.Celsius).In(system.Temperature) - units.TemperatureIn(0, units.Celsius).In(system.Temperature))
+	speed := units.SpeedIn(t.WindSpeed, units.MetersPerSecond).In(system.Speed)
+
+	r := reviser{
+		tf:          newTimeFormat(current, opts),
+		unitsFormat: current.Units(),
+		now:         current.Currently.Time,
+	}
+	revisions := []Revision{}
+	revisions = append(revisions, r.daily(previous, current, degrees, t.PrecipProbability, speed)...)
+	revisions = append(revisions, r.hourly(previous, current, degrees, t.PrecipProbability)...)
+	return revisions
+}
+
+// reviser describes the revisions of a forecast.
+type reviser struct {
+	tf          timeFormat
+	unitsFormat UnitMeasures
+	now         int64
+}
+
+// daysFrom returns the number of days from today to the day of the time,
+// in the time zone of the forecast.
+func (r reviser) daysFrom(seconds int64) int {
+	day := func(seconds int64) time.Time {
+		t := time.Unix(seconds, 0).In(r.tf.loc)
+		return time.Date(t.Year(), t.Month(), t.Day(), 12, 0, 0, 0, r.tf.loc)
+	}
+	return int(math.Round(day(seconds).Sub(day(r.now)).Hours() / 24))
+}
+
+// dayName returns the name of the day relative to now, e.g. "today" or
+// "Saturday".
+func (r reviser) dayName(seconds int64) string {
+	switch r.daysFrom(seconds) {
+	case 0:
+		return "today"
+	case 1:
+		return "tomorrow"
+	}
+	return r.tf.format(seconds, "Monday")
+}
+
+// possessive returns the name of the day relative to now to start a
+// sentence with, e.g. "Today's" or "Saturday's".
+func (r reviser) possessive(seconds int64) string {
+	name := r.dayName(seconds)
+	return strings.ToUpper(name[:1]) + name[1:] + "'s"
+}
+
+// span describes a run of hours, e.g. "at 3pm today" or "from 3pm to 6pm
+// tomorrow".
+func (r reviser) span(start, until int64) string {
+	if until-start <= 60*60 {
+		return fmt.Sprintf("at %s %s", r.tf.hour(start), r.dayName(start))
+	}
+	if r.dayName(start) == r.dayName(until) {
+		return fmt.Sprintf("from %s to %s %s", r.tf.hour(start), r.tf.hour(until), r.dayName(start))
+	}
+	return fmt.Sprintf("from %s %s to %s %s", r.tf.hour(start), r.dayName(start), r.tf.hour(until), r.dayName(until))
+}
+
+// direction describes the direction of a revision.
+func direction(from, to float64, up, down string) string {
+	if to > from {
+		return up
+	}
+	return down
+}
+
+// precipName returns the name of the precipitation of the data point.
+func precipName(weather Weather) string {
+	if weather.PrecipType == "" {
+		return "rain"
+	}
+	return weather.PrecipType
+}
+
+// daily returns the revisions of the days from today on.
+func (r reviser) daily(previous, current Forecast, degrees, probability, speed float64) []Revision {
+	before := map[int64]Weather{}
+	for _, day := range previous.Daily.Data {
+		before[day.Time] = day
+	}
+
+	var revisions []Revision
+	for _, day := range current.Daily.Data {
+		old, ok := before[day.Time]
+		if !ok || r.daysFrom(day.Time) < 0 {
+			continue
+		}
+		revise := func(field string, from, to float64, message string) {
+			revisions = append(revisions, Revision{Time: day.Time, Field: field, From: from, To: to, Message: message})
+		}
+
+		if probability > 0 && math.Abs(day.PrecipProbability-old.PrecipProbability) >= probability {
+			revise("precipProbability", old.PrecipProbability, day.PrecipProbability,
+				fmt.Sprintf("%s %s chance %s from %.0f%% to %.0f%%", r.possessive(day.Time), precipName(day),
+					direction(old.PrecipProbability, day.PrecipProbability, "rose", "fell"),
+					old.PrecipProbability*100, day.PrecipProbability*100))
+		}
+		if degrees > 0 && math.Abs(day.TemperatureMax-old.TemperatureMax) >= degrees {
+			revise("temperatureMax", old.TemperatureMax, day.TemperatureMax,
+				fmt.Sprintf("High for %s revised %s %.0f%s", r.dayName(day.Time),
+					direction(old.TemperatureMax, day.TemperatureMax, "up", "down"),
+					math.Abs(day.TemperatureMax-old.TemperatureMax), r.unitsFormat.Degrees))
+		}
+		if degrees > 0 && math.Abs(day.TemperatureMin-old.TemperatureMin) >= degrees {
+			revise("temperatureMin", old.TemperatureMin, day.TemperatureMin,
+				fmt.Sprintf("Low for %s revised %s %.0f%s", r.dayName(day.Time),
+					direction(old.TemperatureMin, day.TemperatureMin, "up", "down"),
+					math.Abs(day.TemperatureMin-old.TemperatureMin), r.unitsFormat.Degrees))
+		}
+		if speed > 0 && math.Abs(day.WindSpeed-old.WindSpeed) >= speed {
+			revise("windSpeed", old.WindSpeed, day.WindSpeed,
+				fmt.Sprintf("Wind for %s revised %s from %.0f to %.0f %s", r.dayName(day.Time),
+					direction(old.WindSpeed, day.WindSpeed, "up", "down"),
+					old.WindSpeed, day.WindSpeed, r.unitsFormat.Speed))
+		}
+	}
+	return revisions
+}
+
+// hourly returns the revisions of the hours from now on, runs of hours
+// revised the same way as one.
+func (r reviser) hourly(previous, current Forecast, degrees, probability float64) []Revision {
+	before := map[int64]Weather{}
+	for _, hour := range previous.Hourly.Data {
+		before[hour.Time] = hour
+	}
+
+	// runs are the runs of hours being revised by field, with From and To
+	// those of the hour revised the most
+	var revisions []Revision
+	runs := map[string]*Revision{}
+	end := func(field string) {
+		if run, ok := runs[field]; ok {
+			revisions = append(revisions, *run)
+			delete(runs, field)
+		}
+	}
+	extend := func(field string, hour Weather, from, to float64, significant bool) {
+		run, ok := runs[field]
+		if ok && (!significant || run.Until != hour.Time || (run.To > run.From) != (to > from)) {
+			end(field)
+			ok = false
+		}
+		switch {
+		case !significant:
+		case ok:
+			run.Until = hour.Time + 60*60
+			if math.Abs(to-from) > math.Abs(run.To-run.From) {
+				run.From, run.To = from, to
+			}
+		default:
+			runs[field] = &Revision{Time: hour.Time, Until: hour.Time + 60*60, Field: field, From: from, To: to}
+		}
+	}
+
+	for _, hour := range current.Hourly.Data {
+		old, ok := before[hour.Time]
+		if !ok || hour.Time+60*60 <= r.now {
+			continue
+		}
+		extend("precipProbability", hour, old.PrecipProbability, hour.PrecipProbability,
+			probability > 0 && math.Abs(hour.PrecipProbability-old.PrecipProbability) >= probability)
+		extend("temperature", hour, old.Temperature, hour.Temperature,
+			degrees > 0 && math.Abs(hour.Temperature-old.Temperature) >= degrees)
+	}
+	end("precipProbability")
+	end("temperature")
+	sort.SliceStable(revisions, func(i, j int) bool { return revisions[i].Time < revisions[j].Time })
+
+	for i, rev := range revisions {
+		switch rev.Field {
+		case "precipProbability":
+			revisions[i].Message = fmt.Sprintf("Rain chance %s %s from %.0f%% to %.0f%%", r.span(rev.Time, rev.Until),
+				direction(rev.From, rev.To, "rose", "fell"), rev.From*100, rev.To*100)
+		case "temperature":
+			revisions[i].Message = fmt.Sprintf("Temperature %s revised %s by up to %.0f%s", r.span(rev.Time, rev.Until),
+				direction(rev.From, rev.To, "up", "down"), math.Abs(rev.To-rev.From), r.unitsFormat.Degrees)
+		}
+	}
+	return revisions
+}
+
+// PrintRevisions pretty prints the revisions of the forecast since the
+// previous one.
+func PrintRevisions(w io.Writer, previous Forecast, revisions []Revision, opts Options) error {
+	tf := newTimeFormat(previous, opts)
+	since := tf.dateTime(previous.Currently.Time)
+	if len(revisions) == 0 {
+		fmt.Fprintf(w, "No significant changes to the forecast since %s.\n", since)
+		return nil
+	}
+
+	fmt.Fprintf(w, "Changes to the forecast since %s:\n", since)
+	for _, rev := range revisions {
+		fmt.Fprintln(w, colorstring.Color("[yellow]* "+rev.Message))
+	}
+	return nil
+}
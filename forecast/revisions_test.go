@@ -0,0 +1,152 @@
+package forecast
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/genuinetools/weather/units"
+)
+
+func TestRevisions(t *testing.T) {
+	testCases := []struct {
+		name string
+		// units converts the forecasts to other units before the change
+		units string
+		// change changes the previous and current forecasts, both
+		// testForecast to begin with
+		change   func(previous, current *Forecast)
+		expected []string
+	}{
+		{
+			name:     "unchanged",
+			change:   func(previous, current *Forecast) {},
+			expected: []string{},
+		},
+		{
+			name: "rain chance rose",
+			change: func(previous, current *Forecast) {
+				for i := 1; i <= 3; i++ {
+					previous.Hourly.Data[i].PrecipProbability = 0.2
+				}
+				// the fourth is not revised enough to be part of the run
+				previous.Hourly.Data[4].PrecipProbability = 0.6
+			},
+			expected: []string{"Rain chance from 10am to 1pm today rose from 20% to 80%"},
+		},
+		{
+			name: "temperature fell",
+			change: func(previous, current *Forecast) {
+				current.Hourly.Data[5].Temperature -= 4
+				current.Hourly.Data[6].Temperature -= 5
+				// below the threshold
+				current.Hourly.Data[8].Temperature -= 2
+			},
+			expected: []string{"Temperature from 2pm to 4pm today revised down by up to 5°C"},
+		},
+		{
+			name: "run that changes direction",
+			change: func(previous, current *Forecast) {
+				current.Hourly.Data[2].Temperature += 4
+				current.Hourly.Data[3].Temperature += 3
+				current.Hourly.Data[4].Temperature -= 4
+			},
+			expected: []string{
+				"Temperature from 11am to 1pm today revised up by up to 4°C",
+				"Temperature at 1pm today revised down by up to 4°C",
+			},
+		},
+		{
+			name: "run across midnight",
+			change: func(previous, current *Forecast) {
+				previous.Hourly.Data[14].PrecipProbability = 0.5
+				previous.Hourly.Data[15].PrecipProbability = 0.6
+			},
+			expected: []string{"Rain chance from 11pm today to 1am tomorrow fell from 60% to 10%"},
+		},
+		{
+			name: "days",
+			change: func(previous, current *Forecast) {
+				previous.Daily.Data[1].PrecipProbability = 0.7
+				current.Daily.Data[2].PrecipType = "snow"
+				current.Daily.Data[2].PrecipProbability = 0.6
+				current.Daily.Data[2].TemperatureMin -= 3
+				current.Daily.Data[3].WindSpeed += 6
+			},
+			expected: []string{
+				"Tomorrow's rain chance fell from 70% to 20%",
+				"Sunday's snow chance rose from 20% to 60%",
+				"Low for Sunday revised down 3°C",
+				"Wind for Monday revised up from 5 to 11 m/s",
+			},
+		},
+		{
+			// the thresholds in °C are 5.4°F
+			name:  "fahrenheit",
+			units: "us",
+			change: func(previous, current *Forecast) {
+				current.Hourly.Data[1].Temperature += 5
+				current.Hourly.Data[3].Temperature += 6
+				current.Daily.Data[1].TemperatureMax -= 6
+				current.Daily.Data[2].TemperatureMax -= 5
+			},
+			expected: []string{
+				"High for tomorrow revised down 6°F",
+				"Temperature at 12pm today revised up by up to 6°F",
+			},
+		},
+		{
+			name: "past hours and days",
+			change: func(previous, current *Forecast) {
+				// now is 9am tomorrow
+				current.Currently.Time += 24 * 60 * 60
+				for i := 0; i <= 24; i++ {
+					current.Hourly.Data[i].Temperature += 10
+				}
+				current.Daily.Data[0].TemperatureMax += 10
+				current.Daily.Data[1].TemperatureMax += 10
+			},
+			expected: []string{
+				"High for today revised up 10°C",
+				"Temperature at 9am today revised up by up to 10°C",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			previous, current := testForecast(), testForecast()
+			if tc.units != "" {
+				previous = previous.Convert(units.Systems[tc.units])
+				current = current.Convert(units.Systems[tc.units])
+			}
+			tc.change(&previous, &current)
+
+			messages := []string{}
+			for _, rev := range Revisions(previous, current, DefaultRevisionThresholds, Options{}) {
+				messages = append(messages, rev.Message)
+			}
+			if !reflect.DeepEqual(messages, tc.expected) {
+				t.Errorf("expected\n%q\ngot\n%q", tc.expected, messages)
+			}
+		})
+	}
+}
+
+func TestRevisionsPreviousUnits(t *testing.T) {
+	// a previous forecast in other units is compared in the current ones
+	previous := testForecast().Convert(units.Systems["us"])
+	current := testForecast()
+	current.Daily.Data[1].TemperatureMax += 3
+
+	revisions := Revisions(previous, current, DefaultRevisionThresholds, Options{})
+	expected := []Revision{{
+		Time:    current.Daily.Data[1].Time,
+		Field:   "temperatureMax",
+		From:    13,
+		To:      16,
+		Message: "High for tomorrow revised up 3°C",
+	}}
+	if !reflect.DeepEqual(revisions, expected) {
+		t.Errorf("expected %+v, got %+v", expected, revisions)
+	}
+}
@@ -0,0 +1,136 @@
+package forecast
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/genuinetools/weather/geocode"
+)
+
+// alertColor is used instead of the icon color when there are alerts.
+const alertColor = "red"
+
+// hexColors map the colors used for the icons to hex codes for status
+// bars. Black is lightened so it is readable on a dark bar.
+var hexColors = map[string]string{
+	"blue":         "#268bd2",
+	"yellow":       "#b58900",
+	"light_yellow": "#f0e68c",
+	"white":        "#eeeeee",
+	"black":        "#93a1a1",
+	"red":          "#dc322f",
+}
+
+// tmuxColors map the colors used for the icons to tmux colour names.
+var tmuxColors = map[string]string{
+	"blue":         "blue",
+	"yellow":       "yellow",
+	"light_yellow": "brightyellow",
+	"white":        "white",
+	"black":        "brightblack",
+	"red":          "red",
+}
+
+// statusText returns the short and full text for a status bar.
+func statusText(forecast Forecast, opts Options) (short, full string) {
+	unitsFormat := forecast.Units()
+	short = fmt.Sprintf("%s %.0f%s", getGlyph(forecast.Currently.Icon), forecast.Currently.Temperature, unitsFormat.Degrees)
+	full = short + " " + forecast.Currently.Summary
+	if n := len(forecast.Alerts); n > 0 && !opts.IgnoreAlerts {
+		full += fmt.Sprintf(" ⚠ %d alert", n)
+		if n > 1 {
+			full += "s"
+		}
+	}
+	return short, full
+}
+
+// statusColor returns the icon color, or the alert color if there are
+// alerts.
+func statusColor(forecast Forecast, opts Options) string {
+	if len(forecast.Alerts) > 0 && !opts.IgnoreAlerts {
+		return alertColor
+	}
+	_, color := getIconDetails(forecast.Currently.Icon)
+	return color
+}
+
+// I3barBlock is a block of the i3bar protocol.
+type I3barBlock struct {
+	Name      string `json:"name"`
+	FullText  string `json:"full_text"`
+	ShortText string `json:"short_text"`
+	Color     string `json:"color,omitempty"`
+	Urgent    bool   `json:"urgent,omitempty"`
+}
+
+// I3barRenderer writes the current weather as an i3bar (or swaybar)
+// protocol block.
+type I3barRenderer struct{}
+
+// Block returns the i3bar block for the forecast.
+func (I3barRenderer) Block(forecast Forecast, opts Options) I3barBlock {
+	short, full := statusText(forecast, opts)
+	color := statusColor(forecast, opts)
+	return I3barBlock{
+		Name:      "weather",
+		FullText:  full,
+		ShortText: short,
+		Color:     hexColors[color],
+		Urgent:    color == alertColor,
+	}
+}
+
+// Render writes the block as a single line of JSON.
+func (r I3barRenderer) Render(w io.Writer, forecast Forecast, geolocation geocode.Geocode, opts Options) error {
+	return json.NewEncoder(w).Encode(r.Block(forecast, opts))
+}
+
+// WaybarRenderer writes the current weather as JSON for a Waybar custom
+// module with "return-type": "json".
+type WaybarRenderer struct{}
+
+// Render writes the Waybar module as a single line of JSON.
+func (WaybarRenderer) Render(w io.Writer, forecast Forecast, geolocation geocode.Geocode, opts Options) error {
+	unitsFormat := forecast.Units()
+	_, full := statusText(forecast, opts)
+
+	tooltip := []string{
+		fmt.Sprintf("%s in %s", forecast.Currently.Summary, geolocation.City),
+		fmt.Sprintf("Feels like %.0f%s", forecast.Currently.ApparentTemperature, unitsFormat.Degrees),
+		fmt.Sprintf("Humidity %.0f%%", forecast.Currently.Humidity*100),
+		fmt.Sprintf("Wind %.0f %s %s", forecast.Currently.WindSpeed, unitsFormat.Speed, getBearingDetails(forecast.Currently.WindBearing)),
+	}
+	if forecast.Hourly.Summary != "" {
+		tooltip = append(tooltip, forecast.Hourly.Summary)
+	}
+
+	class := strings.Replace(forecast.Currently.Icon, "_", "-", -1)
+	if len(forecast.Alerts) > 0 && !opts.IgnoreAlerts {
+		class = "alert"
+		for _, alert := range forecast.Alerts {
+			tooltip = append(tooltip, "⚠ "+alert.Title)
+		}
+	}
+
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"text":       full,
+		"tooltip":    strings.Join(tooltip, "\n"),
+		"class":      class,
+		"alt":        forecast.Currently.Icon,
+		"percentage": int(forecast.Currently.PrecipProbability * 100),
+	})
+}
+
+// TmuxRenderer writes the current weather with tmux #[fg=...] colour codes
+// for the status line.
+type TmuxRenderer struct{}
+
+// Render writes the status line text.
+func (TmuxRenderer) Render(w io.Writer, forecast Forecast, geolocation geocode.Geocode, opts Options) error {
+	_, full := statusText(forecast, opts)
+	_, err := fmt.Fprintf(w, "#[fg=%s]%s#[default]\n", tmuxColors[statusColor(forecast, opts)], full)
+	return err
+}
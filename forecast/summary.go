@@ -0,0 +1,132 @@
+package forecast
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/mitchellh/colorstring"
+)
+
+// WindowSummary summarises the forecast for a window of time.
+type WindowSummary struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+	// Daily is true if the window is beyond the hourly forecast and is
+	// summarised from the daily forecast for its day.
+	Daily             bool    `json:"daily"`
+	PrecipProbability float64 `json:"precipProbability"`
+	PrecipType        string  `json:"precipType,omitempty"`
+	TemperatureMin    float64 `json:"temperatureMin"`
+	TemperatureMax    float64 `json:"temperatureMax"`
+	WindGust          float64 `json:"windGust"`
+	Alerts            []Alert `json:"alerts"`
+}
+
+// Horizon returns the end of the forecast, the end of its last day.
+func (f Forecast) Horizon() time.Time {
+	end := f.Currently.Time
+	if n := len(f.Hourly.Data); n > 0 {
+		end = f.Hourly.Data[n-1].Time + 60*60
+	}
+	if n := len(f.Daily.Data); n > 0 {
+		end = f.Daily.Data[n-1].Time + 24*60*60
+	}
+	return time.Unix(end, 0)
+}
+
+// SummarizeWindow summarises the forecast between start and end: the worst
+// chance of precipitation, the lowest and highest temperatures, the
+// strongest gusts and the alerts in effect. The hours are used where the
+// hourly forecast covers the window, otherwise the day it starts on. It
+// returns false if the forecast does not cover the window.
+func SummarizeWindow(forecast Forecast, start, end time.Time) (WindowSummary, bool) {
+	summary := WindowSummary{Start: start.Unix(), End: end.Unix(), Alerts: []Alert{}}
+
+	data := forecast.hours(start, end)
+	hourlyEnd := forecast.Currently.Time
+	if n := len(forecast.Hourly.Data); n > 0 {
+		hourlyEnd = forecast.Hourly.Data[n-1].Time + 60*60
+	}
+	if len(data) == 0 || end.Unix() > hourlyEnd {
+		data = nil
+		for _, daily := range forecast.Daily.Data {
+			if daily.Time <= start.Unix() && start.Unix() < daily.Time+24*60*60 {
+				data = []Weather{daily}
+				summary.Daily = true
+				break
+			}
+		}
+		if data == nil {
+			return summary, false
+		}
+	}
+
+	summary.TemperatureMin, summary.TemperatureMax = math.Inf(1), math.Inf(-1)
+	for _, weather := range data {
+		if weather.PrecipProbability >= summary.PrecipProbability {
+			summary.PrecipProbability = weather.PrecipProbability
+			if weather.PrecipType != "" {
+				summary.PrecipType = weather.PrecipType
+			}
+		}
+		min, max := weather.Temperature, weather.Temperature
+		if summary.Daily {
+			min, max = weather.TemperatureMin, weather.TemperatureMax
+		}
+		summary.TemperatureMin = math.Min(summary.TemperatureMin, min)
+		summary.TemperatureMax = math.Max(summary.TemperatureMax, max)
+		summary.WindGust = math.Max(summary.WindGust, math.Max(weather.WindGust, weather.WindSpeed))
+	}
+
+	for _, alert := range forecast.Alerts {
+		if alert.Time < summary.End && (alert.Expires == 0 || alert.Expires > summary.Start) {
+			summary.Alerts = append(summary.Alerts, alert)
+		}
+	}
+	return summary, true
+}
+
+// PrintWindowSummaries pretty prints the summaries of the windows named
+// name.
+func PrintWindowSummaries(w io.Writer, forecast Forecast, name string, summaries []WindowSummary, opts Options) error {
+	unitsFormat := forecast.Units()
+	tf := newTimeFormat(forecast, opts)
+
+	for _, summary := range summaries {
+		when := tf.date(summary.Start) + " " + tf.format(summary.Start, tf.clock()) + "-" + tf.time(summary.End)
+		fmt.Fprintf(w, "%s %s\n", colorstring.Color("[green]"+name), colorstring.Color("[magenta]"+when))
+		if summary.Daily {
+			fmt.Fprintln(w, "  Beyond the hourly forecast, this is the forecast for the day")
+		}
+
+		precipType := summary.PrecipType
+		if precipType == "" {
+			precipType = "precipitation"
+		}
+		fmt.Fprintf(w, "  The chance of %s is at most %s\n", precipType, colorstring.Color(fmt.Sprintf("[bold]%.0f%%", summary.PrecipProbability*100)))
+		min := colorstring.Color(fmt.Sprintf("[blue]%.0f%s", summary.TemperatureMin, unitsFormat.Degrees))
+		max := colorstring.Color(fmt.Sprintf("[blue]%.0f%s", summary.TemperatureMax, unitsFormat.Degrees))
+		if min == max {
+			fmt.Fprintf(w, "  The temperature is %s\n", min)
+		} else {
+			fmt.Fprintf(w, "  The temperature is between %s and %s\n", min, max)
+		}
+		if summary.WindGust > 0 {
+			fmt.Fprintf(w, "  The wind gusts up to %s\n", colorstring.Color(fmt.Sprintf("[bold]%.0f %s", summary.WindGust, unitsFormat.Speed)))
+		}
+		if !opts.IgnoreAlerts {
+			for _, alert := range summary.Alerts {
+				if alert.Expires == 0 {
+					fmt.Fprintf(w, "  %s\n", colorstring.Color("[red]"+alert.Title))
+					continue
+				}
+				fmt.Fprintf(w, "  %s until %s\n", colorstring.Color("[red]"+alert.Title), tf.dateTime(alert.Expires))
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package forecast
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/genuinetools/weather/geocode"
+)
+
+// TemplateData is the view model templates are executed against, e.g.
+//
+//	{{.Currently.Temperature}}{{.Units.Degrees}} {{.Currently.Summary}}
+type TemplateData struct {
+	Forecast
+	Geocode geocode.Geocode
+	Units   UnitMeasures
+}
+
+// TemplateRenderer executes a text/template against the forecast.
+type TemplateRenderer struct {
+	tmpl *template.Template
+}
+
+// NewTemplateRenderer parses the template text.
+func NewTemplateRenderer(text string) (*TemplateRenderer, error) {
+	tmpl, err := template.New("weather").Funcs(TemplateFuncs(time.Local)).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &TemplateRenderer{tmpl: tmpl}, nil
+}
+
+// Render executes the template, adding a trailing newline if the template
+// does not end with one.
+func (r *TemplateRenderer) Render(w io.Writer, forecast Forecast, geolocation geocode.Geocode, opts Options) error {
+	// bind the time helpers to the time zone of the forecast location
+	tmpl, err := r.tmpl.Clone()
+	if err != nil {
+		return err
+	}
+	tmpl.Funcs(TemplateFuncs(forecast.Location()))
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, TemplateData{
+		Forecast: forecast.WithComfort(opts.thresholds()).WithAdvice(opts.Rules, opts.Window),
+		Geocode:  geolocation,
+		Units:    forecast.Units(),
+	}); err != nil {
+		return err
+	}
+	if !bytes.HasSuffix(b.Bytes(), []byte("\n")) {
+		b.WriteByte('\n')
+	}
+
+	_, err = w.Write(b.Bytes())
+	return err
+}
+
+// TemplateFuncs returns the helper functions available to templates, with
+// times formatted in loc.
+func TemplateFuncs(loc *time.Location) template.FuncMap {
+	return template.FuncMap{
+		// bearing returns the compass direction for degrees, e.g. NNE.
+		"bearing": getBearingDetails,
+		// icon returns the colored ascii art for an icon.
+		"icon": func(icon string) string {
+			s, _ := getIcon(icon)
+			return s
+		},
+		// glyph returns a single character symbol for an icon.
+		"glyph": getGlyph,
+		// sparkline draws the field of each data point, e.g.
+		// {{sparkline (slice .Hourly.Data 0 12) "temperature"}}.
+		"sparkline": func(data []Weather, field string) string {
+			return sparkline(fieldValues(data, field))
+		},
+		// time formats a unix timestamp with a Go time layout.
+		"time": func(seconds int64, layout string) string {
+			return time.Unix(seconds, 0).In(loc).Format(layout)
+		},
+		// round rounds to the number of decimal places, 0 if omitted.
+		"round": func(f float64, places ...int) float64 {
+			p := 0
+			if len(places) > 0 {
+				p = places[0]
+			}
+			pow := math.Pow(10, float64(p))
+			return math.Round(f*pow) / pow
+		},
+		// percent formats a 0-1 probability as a percentage, e.g. 42%.
+		"percent": func(f float64) string {
+			return fmt.Sprintf("%.0f%%", f*100)
+		},
+	}
+}
+
+// fieldValues returns the numeric value of the field, by its json name,
+// for each data point.
+func fieldValues(data []Weather, field string) []float64 {
+	index, ok := weatherFields[strings.ToLower(field)]
+	if !ok {
+		return nil
+	}
+
+	values := make([]float64, 0, len(data))
+	for _, weather := range data {
+		v := reflect.ValueOf(weather).Field(index)
+		switch v.Kind() {
+		case reflect.Float64:
+			values = append(values, v.Float())
+		case reflect.Int64:
+			values = append(values, float64(v.Int()))
+		}
+	}
+	return values
+}
+
+// sparkline draws the values scaled between their minimum and maximum.
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	ticks := []rune("▁▂▃▄▅▆▇█")
+	min, max := values[0], values[0]
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		t := 0
+		if max > min {
+			t = int((v - min) / (max - min) * float64(len(ticks)-1))
+		}
+		b.WriteRune(ticks[t])
+	}
+	return b.String()
+}
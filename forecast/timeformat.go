@@ -0,0 +1,248 @@
+package forecast
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Locale describes how dates are written in a locale.
+type Locale struct {
+	// Date is the layout of a date with the day of the week.
+	Date string
+	// Day is the layout of a date without the day of the week.
+	Day string
+	// ShortDate is the layout of an abbreviated date for tables.
+	ShortDate string
+	// At joins a date and the time of day.
+	At string
+	// Clock24 is true if the locale uses the 24-hour clock.
+	Clock24 bool
+
+	// Months, ShortMonths, Days and ShortDays translate the English names
+	// Go formats dates with. They are left empty for English.
+	Months      [12]string
+	ShortMonths [12]string
+	Days        [7]string
+	ShortDays   [7]string
+}
+
+// DefaultLocale is the locale dates are written in if none is set.
+const DefaultLocale = "en-US"
+
+// Locales holds the available locales by name.
+var Locales = map[string]Locale{
+	"en-US": {
+		Date:      "January 2 (Monday)",
+		Day:       "January 2",
+		ShortDate: "Mon Jan 2",
+		At:        " at ",
+	},
+	"en-GB": {
+		Date:      "Monday 2 January",
+		Day:       "2 January",
+		ShortDate: "Mon 2 Jan",
+		At:        " at ",
+		Clock24:   true,
+	},
+	"de-DE": {
+		Date:        "Monday, 2. January",
+		Day:         "2. January",
+		ShortDate:   "Mon 2.1.",
+		At:          " um ",
+		Clock24:     true,
+		Months:      [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		ShortMonths: [12]string{"Jan", "Feb", "Mär", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Okt", "Nov", "Dez"},
+		Days:        [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		ShortDays:   [7]string{"So", "Mo", "Di", "Mi", "Do", "Fr", "Sa"},
+	},
+	"es-ES": {
+		Date:        "Monday, 2 de January",
+		Day:         "2 de January",
+		ShortDate:   "Mon 2 Jan",
+		At:          " a las ",
+		Clock24:     true,
+		Months:      [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		ShortMonths: [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sep", "oct", "nov", "dic"},
+		Days:        [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		ShortDays:   [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+	},
+	"fr-FR": {
+		Date:        "Monday 2 January",
+		Day:         "2 January",
+		ShortDate:   "Mon 2 Jan",
+		At:          " à ",
+		Clock24:     true,
+		Months:      [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		ShortMonths: [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		Days:        [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		ShortDays:   [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+	},
+	"iso": {
+		Date:      "2006-01-02 (Monday)",
+		Day:       "2006-01-02",
+		ShortDate: "Mon 01-02",
+		At:        " ",
+		Clock24:   true,
+	},
+}
+
+// LocaleNames returns the names of the available locales in sorted order.
+func LocaleNames() []string {
+	names := make([]string, 0, len(Locales))
+	for name := range Locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// names returns a replacer translating the English month and day names,
+// or nil for English.
+func (l Locale) names() *strings.Replacer {
+	if l.Months[0] == "" {
+		return nil
+	}
+
+	// the full names go first so they are not matched as abbreviations
+	var pairs []string
+	for i := range l.Months {
+		pairs = append(pairs, time.Month(i+1).String(), l.Months[i])
+	}
+	for i := range l.Days {
+		pairs = append(pairs, time.Weekday(i).String(), l.Days[i])
+	}
+	for i := range l.ShortMonths {
+		pairs = append(pairs, time.Month(i + 1).String()[:3], l.ShortMonths[i])
+	}
+	for i := range l.ShortDays {
+		pairs = append(pairs, time.Weekday(i).String()[:3], l.ShortDays[i])
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// timeFormat formats the timestamps of a forecast in the time zone of the
+// forecast location.
+type timeFormat struct {
+	loc *time.Location
+	// local is also shown, if set and different to loc.
+	local   *time.Location
+	locale  Locale
+	clock24 bool
+	names   *strings.Replacer
+}
+
+// newTimeFormat returns the time format for the forecast and options.
+func newTimeFormat(forecast Forecast, opts Options) timeFormat {
+	locale, ok := Locales[opts.Locale]
+	if !ok {
+		locale = Locales[DefaultLocale]
+	}
+
+	f := timeFormat{
+		loc:     forecast.Location(),
+		locale:  locale,
+		clock24: locale.Clock24,
+		names:   locale.names(),
+	}
+	switch opts.Clock {
+	case 12:
+		f.clock24 = false
+	case 24:
+		f.clock24 = true
+	}
+	if opts.LocalTime {
+		f.local = time.Local
+	}
+	return f
+}
+
+// format formats the timestamp with the layout in the location's zone.
+func (f timeFormat) format(seconds int64, layout string) string {
+	return f.formatIn(seconds, layout, f.loc)
+}
+
+func (f timeFormat) formatIn(seconds int64, layout string, loc *time.Location) string {
+	s := time.Unix(seconds, 0).In(loc).Format(layout)
+	if f.names != nil {
+		s = f.names.Replace(s)
+	}
+	return s
+}
+
+// clock returns the layout of the time of day.
+func (f timeFormat) clock() string {
+	if f.clock24 {
+		return "15:04"
+	}
+	return "3:04pm"
+}
+
+// withLocal adds the time in the local time zone, if it is to be shown and
+// differs from the location's.
+func (f timeFormat) withLocal(seconds int64, s string) string {
+	if f.local == nil {
+		return s
+	}
+
+	t := time.Unix(seconds, 0)
+	name, offset := t.In(f.loc).Zone()
+	localName, localOffset := t.In(f.local).Zone()
+	if name == localName && offset == localOffset {
+		return s
+	}
+	return s + " (" + f.formatIn(seconds, f.clock()+" MST", f.local) + ")"
+}
+
+// dateTime formats the date and time, e.g. "January 2 at 3:04pm MST".
+func (f timeFormat) dateTime(seconds int64) string {
+	return f.withLocal(seconds, f.format(seconds, f.locale.Day+f.locale.At+f.clock()+" MST"))
+}
+
+// date formats the date with the day of the week, e.g.
+// "January 2 (Monday)".
+func (f timeFormat) date(seconds int64) string {
+	return f.format(seconds, f.locale.Date)
+}
+
+// shortDate formats an abbreviated date, e.g. "Mon Jan 2".
+func (f timeFormat) shortDate(seconds int64) string {
+	return f.format(seconds, f.locale.ShortDate)
+}
+
+// weekday formats the abbreviated day of the week, e.g. "Mon".
+func (f timeFormat) weekday(seconds int64) string {
+	return f.format(seconds, "Mon")
+}
+
+// time formats the time of day, e.g. "3:04pm MST".
+func (f timeFormat) time(seconds int64) string {
+	return f.withLocal(seconds, f.format(seconds, f.clock()+" MST"))
+}
+
+// hour formats the hour for labels, e.g. "3pm" or "15:00".
+func (f timeFormat) hour(seconds int64) string {
+	if f.clock24 {
+		return f.format(seconds, "15:00")
+	}
+	return f.format(seconds, "3pm")
+}
+
+// sparklineHour formats the hour in three characters to label the
+// precipitation sparkline, e.g. "3p " or "15 ".
+func (f timeFormat) sparklineHour(seconds int64) string {
+	s := f.format(seconds, "15")
+	if !f.clock24 {
+		s = f.format(seconds, "3pm")
+		s = s[:len(s)-1]
+	}
+	if len(s) == 2 {
+		s += " "
+	}
+	return s
+}
+
+// hourOfDay returns the hour of the day in the location's zone.
+func (f timeFormat) hourOfDay(seconds int64) int {
+	return time.Unix(seconds, 0).In(f.loc).Hour()
+}
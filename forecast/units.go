@@ -0,0 +1,221 @@
+package forecast
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/genuinetools/weather/units"
+)
+
+// unitLabels are the words used for units in the output where they differ
+// from the names of the units.
+var unitLabels = map[string]string{
+	units.Celsius:       "°C",
+	units.Fahrenheit:    "°F",
+	units.Kilometers:    "kilometers",
+	units.Miles:         "miles",
+	units.InchesPerHour: "in/hr",
+}
+
+// unitLabel returns the word used for the unit in the output.
+func unitLabel(unit string) string {
+	if label, ok := unitLabels[unit]; ok {
+		return label
+	}
+	return unit
+}
+
+// unitMeasures returns the terms for the units of the system.
+func unitMeasures(system units.System) UnitMeasures {
+	return UnitMeasures{
+		Degrees:       unitLabel(system.Temperature),
+		Speed:         unitLabel(system.Speed),
+		Length:        unitLabel(system.Distance),
+		Precipitation: unitLabel(system.Precipitation),
+		Pressure:      unitLabel(system.Pressure),
+	}
+}
+
+// System returns the units the values of the forecast are in.
+func (f Forecast) System() units.System {
+	if f.Flags.System != nil {
+		return *f.Flags.System
+	}
+	if system, ok := units.Systems[f.Flags.Units]; ok {
+		return system
+	}
+	return units.Systems[units.Canonical]
+}
+
+// Units returns the terms for the units the values of the forecast are in.
+func (f Forecast) Units() UnitMeasures {
+	return unitMeasures(f.System())
+}
+
+// Convert returns a copy of the forecast with the values converted to the
+// units of the system.
+func (f Forecast) Convert(system units.System) Forecast {
+	from := f.System()
+	if from == system {
+		return f
+	}
+
+	f.Currently = convertWeather(f.Currently, from, system, pointTemperatures)
+	for _, block := range []struct {
+		*TimeDelimited
+		temperatures func(*Weather) []*float64
+	}{
+		{&f.Minutely, nil},
+		{&f.Hourly, pointTemperatures},
+		{&f.Daily, dailyTemperatures},
+	} {
+		data := make([]Weather, len(block.Data))
+		for i, weather := range block.Data {
+			data[i] = convertWeather(weather, from, system, block.temperatures)
+		}
+		block.Data = data
+	}
+
+	f.Flags.Units = system.Name()
+	f.Flags.System = &system
+	return f
+}
+
+// pointTemperatures returns the temperatures of a current, minutely or
+// hourly data point.
+func pointTemperatures(w *Weather) []*float64 {
+	return []*float64{&w.Temperature, &w.ApparentTemperature, &w.DewPoint}
+}
+
+// dailyTemperatures returns the temperatures of a daily data point.
+func dailyTemperatures(w *Weather) []*float64 {
+	return []*float64{&w.TemperatureMax, &w.TemperatureMin, &w.ApparentTemperatureMax, &w.ApparentTemperatureMin, &w.DewPoint}
+}
+
+// convertWeather converts the values of the weather between the systems.
+// Only the temperatures the data point has are converted, so the missing
+// ones stay zero rather than become 32°F.
+func convertWeather(w Weather, from, to units.System, temperatures func(*Weather) []*float64) Weather {
+	temperature := func(v float64) float64 {
+		return units.Round(units.TemperatureIn(v, from.Temperature).In(to.Temperature))
+	}
+	speed := func(v float64) float64 {
+		return units.Round(units.SpeedIn(v, from.Speed).In(to.Speed))
+	}
+	distance := func(v float64) float64 {
+		return units.Round(units.DistanceIn(v, from.Distance).In(to.Distance))
+	}
+	precip := func(v float64) float64 {
+		// intensities are small, so keep more of the precision
+		return units.PrecipRateIn(v, from.Precipitation).In(to.Precipitation)
+	}
+
+	if temperatures != nil {
+		for _, v := range temperatures(&w) {
+			*v = temperature(*v)
+		}
+	}
+	w.WindSpeed = speed(w.WindSpeed)
+	w.WindGust = speed(w.WindGust)
+	w.Visibility = distance(w.Visibility)
+	w.NearestStormDistance = distance(w.NearestStormDistance)
+	w.Pressure = units.Round(units.PressureIn(w.Pressure, from.Pressure).In(to.Pressure))
+	w.PrecipIntensity = precip(w.PrecipIntensity)
+	w.PrecipIntensityMax = precip(w.PrecipIntensityMax)
+	// the comfort metrics are derived again in the new units
+	w.Comfort = nil
+	return w
+}
+
+// The fields of the data points of a response by the quantity they hold,
+// for converting responses without decoding them into a Forecast.
+var (
+	temperatureFields = []string{
+		"temperature", "apparentTemperature", "dewPoint",
+		"temperatureMax", "temperatureMin", "temperatureHigh", "temperatureLow",
+		"apparentTemperatureMax", "apparentTemperatureMin", "apparentTemperatureHigh", "apparentTemperatureLow",
+	}
+	speedFields    = []string{"windSpeed", "windGust"}
+	distanceFields = []string{"visibility", "nearestStormDistance"}
+	pressureFields = []string{"pressure"}
+	precipFields   = []string{"precipIntensity", "precipIntensityMax", "precipIntensityError"}
+)
+
+// ConvertJSON converts the values of a response from the API in the
+// canonical units to the units of the system. Unlike Convert it works on
+// the JSON itself, so the fields a Forecast does not have are kept.
+func ConvertJSON(body []byte, system units.System) ([]byte, error) {
+	from := units.Systems[units.Canonical]
+	if system == from {
+		return body, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// keep the numbers that are not converted exactly as they were
+	decoder.UseNumber()
+	var f map[string]interface{}
+	if err := decoder.Decode(&f); err != nil {
+		return nil, err
+	}
+
+	convertPoint(f["currently"], from, system)
+	for _, block := range []string{"minutely", "hourly", "daily"} {
+		if b, ok := f[block].(map[string]interface{}); ok {
+			if data, ok := b["data"].([]interface{}); ok {
+				for _, point := range data {
+					convertPoint(point, from, system)
+				}
+			}
+		}
+	}
+
+	flags, ok := f["flags"].(map[string]interface{})
+	if !ok {
+		flags = map[string]interface{}{}
+		f["flags"] = flags
+	}
+	flags["units"] = system.Name()
+	flags["system"] = system
+
+	return json.Marshal(f)
+}
+
+// convertPoint converts the values of a data point decoded from JSON
+// between the systems, like convertWeather. Only the fields the data point
+// has are converted.
+func convertPoint(point interface{}, from, to units.System) {
+	p, ok := point.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	convert := func(fields []string, fn func(float64) float64) {
+		for _, field := range fields {
+			n, ok := p[field].(json.Number)
+			if !ok {
+				continue
+			}
+			v, err := n.Float64()
+			if err != nil {
+				continue
+			}
+			p[field] = fn(v)
+		}
+	}
+	convert(temperatureFields, func(v float64) float64 {
+		return units.Round(units.TemperatureIn(v, from.Temperature).In(to.Temperature))
+	})
+	convert(speedFields, func(v float64) float64 {
+		return units.Round(units.SpeedIn(v, from.Speed).In(to.Speed))
+	})
+	convert(distanceFields, func(v float64) float64 {
+		return units.Round(units.DistanceIn(v, from.Distance).In(to.Distance))
+	})
+	convert(pressureFields, func(v float64) float64 {
+		return units.Round(units.PressureIn(v, from.Pressure).In(to.Pressure))
+	})
+	convert(precipFields, func(v float64) float64 {
+		// intensities are small, so keep more of the precision
+		return units.PrecipRateIn(v, from.Precipitation).In(to.Precipitation)
+	})
+}
@@ -0,0 +1,56 @@
+package forecast
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/genuinetools/weather/units"
+)
+
+func TestConvertJSON(t *testing.T) {
+	body := []byte(`{"latitude":40.7,"timezone":"America/New_York",` +
+		`"currently":{"time":1709280000,"temperature":10,"windSpeed":5,"pressure":1013.25,"visibility":16.09,"precipIntensity":2.54,"ozone":310.2},` +
+		`"hourly":{"summary":"Rain.","data":[{"time":1709280000,"temperature":0,"dewPoint":-5,"precipIntensityError":5.08}]},` +
+		`"daily":{"data":[{"time":1709251200,"temperatureHigh":20,"temperatureLow":-10,"windGust":10}]},` +
+		`"flags":{"units":"si","sources":["cmc","gfs"],"nearest-station":1.2}}`)
+
+	// the canonical units are passed on untouched
+	b, err := ConvertJSON(body, units.Systems["si"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != string(body) {
+		t.Errorf("expected the si response as it was, got %s", b)
+	}
+
+	b, err = ConvertJSON(body, units.Systems["us"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	var expected map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"latitude":40.7,"timezone":"America/New_York",`+
+		`"currently":{"time":1709280000,"temperature":50,"windSpeed":11.18,"pressure":1013.25,"visibility":10,"precipIntensity":0.1,"ozone":310.2},`+
+		`"hourly":{"summary":"Rain.","data":[{"time":1709280000,"temperature":32,"dewPoint":23,"precipIntensityError":0.2}]},`+
+		`"daily":{"data":[{"time":1709251200,"temperatureHigh":68,"temperatureLow":14,"windGust":22.37}]},`+
+		`"flags":{"units":"us","sources":["cmc","gfs"],"nearest-station":1.2,`+
+		`"system":{"temperature":"F","speed":"mph","distance":"mi","pressure":"mbar","precipitation":"in/h"}}}`), &expected); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected\n%v\ngot\n%v", expected, got)
+	}
+
+	// the response still decodes into a forecast in the units
+	var fc Forecast
+	if err := json.Unmarshal(b, &fc); err != nil {
+		t.Fatal(err)
+	}
+	if fc.System() != units.Systems["us"] || fc.Currently.Temperature != 50 {
+		t.Errorf("expected the forecast in us units at 50°F, got %+v at %g", fc.System(), fc.Currently.Temperature)
+	}
+}
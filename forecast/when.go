@@ -0,0 +1,274 @@
+package forecast
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/genuinetools/weather/geocode"
+)
+
+// When is a time named in plain language, e.g. "tomorrow", "saturday 3pm",
+// "tonight", "in 6 hours" or "2024-03-02T15:00".
+type When struct {
+	// Expr is the expression When was parsed from.
+	Expr string
+
+	// resolve returns the time named relative to now, and whether it
+	// names a whole day rather than a time of day.
+	resolve func(now time.Time) (time.Time, bool)
+}
+
+var (
+	// inRegex matches relative times like "in 6 hours" or "in 2d".
+	inRegex = regexp.MustCompile(`^in\s+(\d+)\s*(m|min|mins|minutes?|h|hrs?|hours?|d|days?)$`)
+	// clockRegex matches times of day like "3pm", "3:30 pm" or "15:00".
+	clockRegex = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+)
+
+// timesOfDay are the times parts of the day are taken to be.
+var timesOfDay = map[string]time.Duration{
+	"morning":   9 * time.Hour,
+	"noon":      12 * time.Hour,
+	"afternoon": 15 * time.Hour,
+	"evening":   19 * time.Hour,
+	"night":     22 * time.Hour,
+	"midnight":  0,
+}
+
+// isoLayouts are the layouts of the ISO 8601 times understood, and whether
+// they name a day.
+var isoLayouts = []struct {
+	layout string
+	day    bool
+}{
+	{time.RFC3339, false},
+	{"2006-01-02T15:04", false},
+	{"2006-01-02 15:04", false},
+	{"2006-01-02", true},
+}
+
+// ParseWhen parses a time named in plain language: now, today, tonight,
+// tomorrow or the name of a day of the week, optionally followed by a time
+// of day like 3pm, 15:00, morning, afternoon or evening, a time of day on
+// its own, "in <n> minutes|hours|days", or an ISO 8601 date or time.
+func ParseWhen(s string) (When, error) {
+	expr := strings.ToLower(strings.Join(strings.Fields(s), " "))
+	when := When{Expr: expr}
+
+	if expr == "now" {
+		when.resolve = func(now time.Time) (time.Time, bool) { return now, false }
+		return when, nil
+	}
+
+	if m := inRegex.FindStringSubmatch(expr); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		unit := time.Hour
+		switch m[2][0] {
+		case 'm':
+			unit = time.Minute
+		case 'd':
+			when.resolve = func(now time.Time) (time.Time, bool) { return now.AddDate(0, 0, n), true }
+			return when, nil
+		}
+		when.resolve = func(now time.Time) (time.Time, bool) { return now.Add(time.Duration(n) * unit), false }
+		return when, nil
+	}
+
+	for _, iso := range isoLayouts {
+		if _, err := time.Parse(iso.layout, strings.ToUpper(expr)); err != nil {
+			continue
+		}
+		iso := iso
+		when.resolve = func(now time.Time) (time.Time, bool) {
+			t, _ := time.ParseInLocation(iso.layout, strings.ToUpper(expr), now.Location())
+			return t, iso.day
+		}
+		return when, nil
+	}
+
+	// a day followed by a time of day, either may be left out
+	rest := expr
+	day := ""
+	if i := strings.IndexByte(rest, ' '); i > 0 {
+		day, rest = rest[:i], rest[i+1:]
+	} else {
+		day, rest = rest, ""
+	}
+	offset, dayOK := dayOffset(day)
+	if !dayOK {
+		// a time of day on its own
+		day, rest = "", expr
+	}
+	clock, clockOK := parseClock(rest)
+	if rest != "" && !clockOK {
+		return when, fmt.Errorf("invalid time %q: expected now, today, tonight, tomorrow, a day of the week, a time of day like 3pm, \"in <n> hours\" or an ISO 8601 date", s)
+	}
+	if day == "tonight" && rest == "" {
+		clock, clockOK = timesOfDay["night"], true
+	}
+
+	when.resolve = func(now time.Time) (time.Time, bool) {
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		date := midnight.AddDate(0, 0, offset(now))
+		if !clockOK {
+			return date, true
+		}
+		t := date.Add(clock)
+		// a time of day on its own is the next one
+		if day == "" && t.Before(now.Add(-time.Hour)) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, false
+	}
+	return when, nil
+}
+
+// dayOffset returns the number of days from today to the day named, for
+// the date of now.
+func dayOffset(day string) (func(now time.Time) int, bool) {
+	switch day {
+	case "today", "tonight":
+		return func(time.Time) int { return 0 }, true
+	case "tomorrow":
+		return func(time.Time) int { return 1 }, true
+	}
+	if d, ok := parseWeekday(day); ok {
+		// the day itself if it is today
+		return func(now time.Time) int { return (int(d) - int(now.Weekday()) + 7) % 7 }, true
+	}
+	return func(time.Time) int { return 0 }, false
+}
+
+// parseClock parses a time of day, as the duration since midnight.
+func parseClock(s string) (time.Duration, bool) {
+	if d, ok := timesOfDay[s]; ok {
+		return d, true
+	}
+
+	m := clockRegex.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	h, _ := strconv.Atoi(m[1])
+	min, _ := strconv.Atoi(m[2])
+	switch {
+	case m[3] == "" && m[2] == "":
+		// a bare number is not a time
+		return 0, false
+	case m[3] != "" && (h < 1 || h > 12):
+		return 0, false
+	case m[3] == "pm" && h != 12:
+		h += 12
+	case m[3] == "am" && h == 12:
+		h = 0
+	}
+	if h > 23 || min > 59 {
+		return 0, false
+	}
+	return time.Duration(h)*time.Hour + time.Duration(min)*time.Minute, true
+}
+
+// Moment is the weather in the forecast at a time named by When.
+type Moment struct {
+	Time time.Time
+	// Daily is true if the weather is the daily forecast for the day of
+	// Time, rather than the hourly forecast.
+	Daily   bool
+	Weather Weather
+	// Note explains the choice of weather, if needed.
+	Note string
+}
+
+// Find returns the weather at the time in the forecast: the hour of a time
+// of day or the day of a day. A time of day beyond the hourly forecast
+// falls back to its day. It errors if the time is outside the forecast.
+func (w When) Find(forecast Forecast) (Moment, error) {
+	loc := forecast.Location()
+	now := time.Unix(forecast.Currently.Time, 0).In(loc)
+	t, daily := w.resolve(now)
+	t = t.In(loc)
+	m := Moment{Time: t, Daily: daily}
+
+	if !daily {
+		switch {
+		case t.Before(now.Add(-time.Hour)):
+			return m, fmt.Errorf("%q is %s, which is in the past", w.Expr, t.Format("Mon Jan 2 3:04pm MST"))
+		case t.Before(now.Add(30 * time.Minute)):
+			m.Weather = forecast.Currently
+			return m, nil
+		}
+		for _, hourly := range forecast.Hourly.Data {
+			if hourly.Time <= t.Unix() && t.Unix() < hourly.Time+60*60 {
+				m.Weather = hourly
+				return m, nil
+			}
+		}
+	}
+
+	for _, day := range forecast.Daily.Data {
+		start := time.Unix(day.Time, 0).In(loc)
+		if !t.Before(start) && t.Before(start.AddDate(0, 0, 1)) {
+			if !daily {
+				m.Note = fmt.Sprintf("%s is beyond the hourly forecast, this is the forecast for the day", t.Format("3:04pm"))
+			}
+			m.Daily = true
+			m.Weather = day
+			return m, nil
+		}
+	}
+
+	layout := "Mon Jan 2 3:04pm MST"
+	if daily {
+		layout = "Mon Jan 2"
+	}
+	if len(forecast.Daily.Data) > 0 && t.Unix() < forecast.Daily.Data[0].Time {
+		return m, fmt.Errorf("%q is %s, which is in the past", w.Expr, t.Format(layout))
+	}
+	return m, fmt.Errorf("%q is %s, beyond the forecast which ends %s", w.Expr, t.Format(layout), forecast.Horizon().In(loc).Format("Mon Jan 2 3:04pm MST"))
+}
+
+// Forecast returns the forecast as of the moment: its weather as the
+// current weather, and the hours, days and alerts from then on.
+func (m Moment) Forecast(forecast Forecast) Forecast {
+	f := forecast
+	f.Currently = m.Weather
+	f.Minutely = TimeDelimited{}
+
+	f.Hourly.Data = nil
+	for _, hourly := range forecast.Hourly.Data {
+		if hourly.Time >= m.Weather.Time {
+			f.Hourly.Data = append(f.Hourly.Data, hourly)
+		}
+	}
+	f.Daily.Data = nil
+	for _, daily := range forecast.Daily.Data {
+		if daily.Time+24*60*60 > m.Weather.Time {
+			f.Daily.Data = append(f.Daily.Data, daily)
+		}
+	}
+
+	end := m.Weather.Time + 60*60
+	if m.Daily {
+		end = m.Weather.Time + 24*60*60
+	}
+	f.Alerts = []Alert{}
+	for _, alert := range forecast.Alerts {
+		if alert.Time < end && (alert.Expires == 0 || alert.Expires > m.Weather.Time) {
+			f.Alerts = append(f.Alerts, alert)
+		}
+	}
+	return f
+}
+
+// PrintMoment pretty prints the weather at the moment in the layout of the
+// current weather.
+func PrintMoment(w io.Writer, forecast Forecast, moment Moment, geolocation geocode.Geocode, opts Options) error {
+	if !moment.Daily && moment.Weather.Time == forecast.Currently.Time {
+		return printCurrent(w, forecast, geolocation, opts)
+	}
+	return printWeather(w, moment.Forecast(forecast), geolocation, opts, &moment)
+}
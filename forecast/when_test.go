@@ -0,0 +1,98 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWhen(t *testing.T) {
+	for _, s := range []string{"someday", "saturday at noon", "25:00", "13pm", "0am", "42", "in hours", "tomorrow 3xm"} {
+		if _, err := ParseWhen(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestWhenFind(t *testing.T) {
+	// testForecast is from 9am on Friday March 1, with 48 hours and 7 days
+	fc := testForecast()
+	loc := fc.Location()
+	at := func(day, hour int) time.Time { return time.Date(2024, time.March, day, hour, 0, 0, 0, loc) }
+
+	testCases := []struct {
+		when string
+		// time is the time named, and weather that of the moment found
+		time    time.Time
+		daily   bool
+		weather int64
+		note    string
+		err     string
+	}{
+		{when: "now", time: at(1, 9), weather: fc.Currently.Time},
+		{when: "in 20 minutes", time: at(1, 9).Add(20 * time.Minute), weather: fc.Currently.Time},
+		{when: "today", time: at(1, 0), daily: true, weather: fc.Daily.Data[0].Time},
+		{when: "Tonight", time: at(1, 22), weather: fc.Hourly.Data[13].Time},
+		{when: "tomorrow", time: at(2, 0), daily: true, weather: fc.Daily.Data[1].Time},
+		{when: "tomorrow morning", time: at(2, 9), weather: fc.Hourly.Data[24].Time},
+		{when: "saturday 3pm", time: at(2, 15), weather: fc.Hourly.Data[30].Time},
+		{when: "sat 3:30 pm", time: at(2, 15).Add(30 * time.Minute), weather: fc.Hourly.Data[30].Time},
+		// today's day of the week is today
+		{when: "friday", time: at(1, 0), daily: true, weather: fc.Daily.Data[0].Time},
+		{when: "thursday", time: at(7, 0), daily: true, weather: fc.Daily.Data[6].Time},
+		{when: "3pm", time: at(1, 15), weather: fc.Hourly.Data[6].Time},
+		{when: "15:00", time: at(1, 15), weather: fc.Hourly.Data[6].Time},
+		// a time of day on its own that has gone is tomorrow's
+		{when: "7am", time: at(2, 7), weather: fc.Hourly.Data[22].Time},
+		{when: "in 6 hours", time: at(1, 15), weather: fc.Hourly.Data[6].Time},
+		{when: "in 2 days", time: at(3, 9), daily: true, weather: fc.Daily.Data[2].Time},
+		{when: "2024-03-02T15:00", time: at(2, 15), weather: fc.Hourly.Data[30].Time},
+		{when: "2024-03-04", time: at(4, 0), daily: true, weather: fc.Daily.Data[3].Time},
+		// beyond the hourly forecast falls back to the day
+		{
+			when:    "monday 3pm",
+			time:    at(4, 15),
+			daily:   true,
+			weather: fc.Daily.Data[3].Time,
+			note:    "3:00pm is beyond the hourly forecast, this is the forecast for the day",
+		},
+		{
+			when: "2024-03-01T06:00",
+			err:  `"2024-03-01t06:00" is Fri Mar 1 6:00am EST, which is in the past`,
+		},
+		{
+			when: "2024-02-28",
+			err:  `"2024-02-28" is Wed Feb 28, which is in the past`,
+		},
+		{
+			when: "in 10 days",
+			err:  `"in 10 days" is Mon Mar 11, beyond the forecast which ends Fri Mar 8 12:00am EST`,
+		},
+		{
+			when: "2024-03-08 15:00",
+			err:  `"2024-03-08 15:00" is Fri Mar 8 3:00pm EST, beyond the forecast which ends Fri Mar 8 12:00am EST`,
+		},
+	}
+
+	for _, tc := range testCases {
+		when, err := ParseWhen(tc.when)
+		if err != nil {
+			t.Errorf("%q: %v", tc.when, err)
+			continue
+		}
+		m, err := when.Find(fc)
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("%q: expected the error %q, got %v", tc.when, tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.when, err)
+			continue
+		}
+		if !m.Time.Equal(tc.time) || m.Daily != tc.daily || m.Weather.Time != tc.weather || m.Note != tc.note {
+			t.Errorf("%q: expected %s daily %t at %d %q, got %s daily %t at %d %q", tc.when,
+				tc.time, tc.daily, tc.weather, tc.note, m.Time, m.Daily, m.Weather.Time, m.Note)
+		}
+	}
+}
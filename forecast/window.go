@@ -0,0 +1,222 @@
+package forecast
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// windowRegex matches windows of the day like "08:00-09:30".
+var windowRegex = regexp.MustCompile(`^\s*(\d{1,2}):(\d{2})\s*-\s*(\d{1,2}):(\d{2})\s*$`)
+
+// Window is a window of the day between two times of day, as durations
+// since midnight. The zero Window is the next 12 hours.
+type Window struct {
+	Start, End time.Duration
+}
+
+// defaultWindowLength is how far ahead the zero Window looks.
+const defaultWindowLength = 12 * time.Hour
+
+// ParseWindow parses a window of the day in the form "HH:MM-HH:MM". A
+// window ending before it starts ends the next day.
+func ParseWindow(s string) (Window, error) {
+	m := windowRegex.FindStringSubmatch(s)
+	if m == nil {
+		return Window{}, fmt.Errorf("invalid window %q: expected \"HH:MM-HH:MM\"", s)
+	}
+
+	clock := func(hour, minute string) (time.Duration, error) {
+		h, _ := strconv.Atoi(hour)
+		min, _ := strconv.Atoi(minute)
+		if h > 23 || min > 59 {
+			return 0, fmt.Errorf("invalid window %q: %s:%s is not a time of day", s, hour, minute)
+		}
+		return time.Duration(h)*time.Hour + time.Duration(min)*time.Minute, nil
+	}
+	start, err := clock(m[1], m[2])
+	if err != nil {
+		return Window{}, err
+	}
+	end, err := clock(m[3], m[4])
+	if err != nil {
+		return Window{}, err
+	}
+	if start == end {
+		return Window{}, fmt.Errorf("invalid window %q: it is empty", s)
+	}
+	return Window{Start: start, End: end}, nil
+}
+
+// Next returns the start and end of the window that is ongoing or next at
+// now in loc, with the start no earlier than now.
+func (win Window) Next(now time.Time, loc *time.Location) (time.Time, time.Time) {
+	if win == (Window{}) {
+		return now, now.Add(defaultWindowLength)
+	}
+
+	now = now.In(loc)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	// the window that started yesterday may not have ended yet
+	for day := -1; ; day++ {
+		start, end := win.on(midnight.AddDate(0, 0, day))
+		if end.After(now) {
+			if start.Before(now) {
+				start = now
+			}
+			return start, end
+		}
+	}
+}
+
+// on returns the start and end of the window on the day starting at
+// midnight.
+func (win Window) on(midnight time.Time) (time.Time, time.Time) {
+	start, end := midnight.Add(win.Start), midnight.Add(win.End)
+	if win.End < win.Start {
+		end = midnight.AddDate(0, 0, 1).Add(win.End)
+	}
+	return start, end
+}
+
+// Schedule is a set of windows recurring on some days of the week, e.g.
+// "weekdays 08:00-09:00 and 17:30-18:30".
+type Schedule struct {
+	// Days are the days of the week the windows start on, by
+	// time.Weekday.
+	Days    [7]bool
+	Windows []Window
+}
+
+// scheduleDays are the names of sets of days in schedules.
+var scheduleDays = map[string][]time.Weekday{
+	"daily":    {time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekends": {time.Saturday, time.Sunday},
+}
+
+// parseWeekday parses the name of a day of the week, e.g. "Monday" or
+// "mon".
+func parseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(s)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || (len(s) >= 3 && strings.HasPrefix(name, s)) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// ParseSchedule parses the days and windows of a schedule. The days are
+// "daily", "weekdays", "weekends", names of days like "mon,wed" or ranges
+// like "mon-fri", and every day if they are left out. The windows are in
+// the form of ParseWindow, separated by "and" or commas.
+func ParseSchedule(s string) (Schedule, error) {
+	var schedule Schedule
+	days := false
+	for _, field := range strings.Fields(strings.Replace(s, ",", " ", -1)) {
+		field = strings.ToLower(field)
+		if field == "and" || field == "every" || field == "day" {
+			continue
+		}
+
+		if windowRegex.MatchString(field) {
+			win, err := ParseWindow(field)
+			if err != nil {
+				return schedule, err
+			}
+			schedule.Windows = append(schedule.Windows, win)
+			continue
+		}
+
+		days = true
+		if set, ok := scheduleDays[field]; ok {
+			for _, d := range set {
+				schedule.Days[d] = true
+			}
+			continue
+		}
+		parts := strings.SplitN(field, "-", 2)
+		from, ok := parseWeekday(parts[0])
+		if !ok {
+			return schedule, fmt.Errorf("invalid schedule %q: %q is not a day or a window", s, field)
+		}
+		to := from
+		if len(parts) == 2 {
+			if to, ok = parseWeekday(parts[1]); !ok {
+				return schedule, fmt.Errorf("invalid schedule %q: %q is not a day", s, parts[1])
+			}
+		}
+		for d := from; ; d = (d + 1) % 7 {
+			schedule.Days[d] = true
+			if d == to {
+				break
+			}
+		}
+	}
+
+	if len(schedule.Windows) == 0 {
+		return schedule, fmt.Errorf("invalid schedule %q: it has no windows, e.g. 08:00-09:00", s)
+	}
+	if !days {
+		schedule.Days = [7]bool{true, true, true, true, true, true, true}
+	}
+	return schedule, nil
+}
+
+// Occurrences returns the start and end of the next n windows of the
+// schedule that are ongoing or start after now, up to until, in order.
+// The ongoing window starts at now.
+func (s Schedule) Occurrences(now, until time.Time, loc *time.Location, n int) [][2]time.Time {
+	now = now.In(loc)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	var occurrences [][2]time.Time
+	for day := -1; len(occurrences) < n; day++ {
+		date := midnight.AddDate(0, 0, day)
+		if date.After(until) {
+			break
+		}
+		if !s.Days[date.Weekday()] {
+			continue
+		}
+
+		var today [][2]time.Time
+		for _, win := range s.Windows {
+			start, end := win.on(date)
+			if !end.After(now) || !start.Before(until) {
+				continue
+			}
+			if start.Before(now) {
+				start = now
+			}
+			today = append(today, [2]time.Time{start, end})
+		}
+		sort.Slice(today, func(i, j int) bool { return today[i][0].Before(today[j][0]) })
+		occurrences = append(occurrences, today...)
+	}
+
+	if len(occurrences) > n {
+		occurrences = occurrences[:n]
+	}
+	return occurrences
+}
+
+// hours returns the current weather and hourly data points overlapping the
+// times, the current weather only if the times include now.
+func (f Forecast) hours(start, end time.Time) []Weather {
+	var data []Weather
+	if now := f.Currently.Time; now >= start.Unix() && now < end.Unix() {
+		data = append(data, f.Currently)
+	}
+	for _, hourly := range f.Hourly.Data {
+		if hourly.Time+60*60 > start.Unix() && hourly.Time < end.Unix() {
+			data = append(data, hourly)
+		}
+	}
+	return data
+}
@@ -0,0 +1,224 @@
+package forecast
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseWindow(t *testing.T) {
+	testCases := []struct {
+		s        string
+		expected Window
+		err      string
+	}{
+		{s: "08:00-09:30", expected: Window{Start: 8 * time.Hour, End: 9*time.Hour + 30*time.Minute}},
+		{s: " 7:15 - 17:45 ", expected: Window{Start: 7*time.Hour + 15*time.Minute, End: 17*time.Hour + 45*time.Minute}},
+		// a window ending before it starts ends the next day
+		{s: "22:00-06:00", expected: Window{Start: 22 * time.Hour, End: 6 * time.Hour}},
+		{s: "00:00-23:59", expected: Window{End: 23*time.Hour + 59*time.Minute}},
+		{s: "8-9", err: `expected "HH:MM-HH:MM"`},
+		{s: "08:00", err: `expected "HH:MM-HH:MM"`},
+		{s: "24:00-06:00", err: "24:00 is not a time of day"},
+		{s: "08:60-09:00", err: "08:60 is not a time of day"},
+		{s: "08:00-08:00", err: "it is empty"},
+	}
+
+	for _, tc := range testCases {
+		win, err := ParseWindow(tc.s)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("ParseWindow(%q): unexpected error: %v", tc.s, err)
+		case tc.err != "" && err == nil:
+			t.Errorf("ParseWindow(%q): expected an error containing %q", tc.s, tc.err)
+		case tc.err != "" && !strings.Contains(err.Error(), tc.err):
+			t.Errorf("ParseWindow(%q): expected an error containing %q, got %v", tc.s, tc.err, err)
+		case win != tc.expected:
+			t.Errorf("ParseWindow(%q): expected %+v, got %+v", tc.s, tc.expected, win)
+		}
+	}
+}
+
+// windowTimes formats the start and end of windows in loc, e.g.
+// "Wed 08:00-09:00".
+func windowTimes(loc *time.Location, times ...[2]time.Time) []string {
+	s := []string{}
+	for _, t := range times {
+		s = append(s, t[0].In(loc).Format("Mon 15:04")+"-"+t[1].In(loc).Format("15:04"))
+	}
+	return s
+}
+
+func TestWindowNext(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	morning := Window{Start: 8 * time.Hour, End: 9 * time.Hour}
+	night := Window{Start: 22 * time.Hour, End: 6 * time.Hour}
+
+	testCases := []struct {
+		name     string
+		window   Window
+		now      time.Time
+		expected string
+	}{
+		{name: "later today", window: morning, now: time.Date(2024, time.March, 6, 7, 30, 0, 0, loc), expected: "Wed 08:00-09:00"},
+		{name: "ongoing", window: morning, now: time.Date(2024, time.March, 6, 8, 15, 0, 0, loc), expected: "Wed 08:15-09:00"},
+		{name: "over for today", window: morning, now: time.Date(2024, time.March, 6, 9, 0, 0, 0, loc), expected: "Thu 08:00-09:00"},
+		{name: "across midnight", window: night, now: time.Date(2024, time.March, 6, 20, 0, 0, 0, loc), expected: "Wed 22:00-06:00"},
+		// the window that started yesterday has not ended
+		{name: "after midnight", window: night, now: time.Date(2024, time.March, 7, 2, 0, 0, 0, loc), expected: "Thu 02:00-06:00"},
+		{name: "the next 12 hours", now: time.Date(2024, time.March, 6, 7, 30, 0, 0, loc), expected: "Wed 07:30-19:30"},
+	}
+
+	for _, tc := range testCases {
+		start, end := tc.window.Next(tc.now, loc)
+		if got := windowTimes(loc, [2]time.Time{start, end}); got[0] != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.expected, got[0])
+		}
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	commute := []Window{{Start: 8 * time.Hour, End: 9 * time.Hour}, {Start: 17*time.Hour + 30*time.Minute, End: 18*time.Hour + 30*time.Minute}}
+	every := [7]bool{true, true, true, true, true, true, true}
+	weekdays := [7]bool{false, true, true, true, true, true, false}
+
+	testCases := []struct {
+		s       string
+		days    [7]bool
+		windows []Window
+		err     string
+	}{
+		{s: "weekdays 08:00-09:00 and 17:30-18:30", days: weekdays, windows: commute},
+		{s: "mon-fri 08:00-09:00, 17:30-18:30", days: weekdays, windows: commute},
+		{s: "Monday-Friday 08:00-09:00 and 17:30-18:30", days: weekdays, windows: commute},
+		// a range may wrap around the end of the week
+		{s: "fri-mon 08:00-09:00", days: [7]bool{true, true, false, false, false, true, true}, windows: commute[:1]},
+		{s: "mon,wed 08:00-09:00", days: [7]bool{false, true, false, true, false, false, false}, windows: commute[:1]},
+		{s: "weekends 08:00-09:00", days: [7]bool{true, false, false, false, false, false, true}, windows: commute[:1]},
+		{s: "every day 08:00-09:00", days: every, windows: commute[:1]},
+		{s: "daily 08:00-09:00", days: every, windows: commute[:1]},
+		{s: "08:00-09:00 and 17:30-18:30", days: every, windows: commute},
+		{s: "sat 22:00-02:00", days: [7]bool{false, false, false, false, false, false, true}, windows: []Window{{Start: 22 * time.Hour, End: 2 * time.Hour}}},
+		{s: "weekdays", err: "it has no windows"},
+		{s: "someday 08:00-09:00", err: `"someday" is not a day or a window`},
+		{s: "mon-someday 08:00-09:00", err: `"someday" is not a day`},
+		{s: "weekdays 08:00-08:00", err: "it is empty"},
+	}
+
+	for _, tc := range testCases {
+		schedule, err := ParseSchedule(tc.s)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("ParseSchedule(%q): unexpected error: %v", tc.s, err)
+		case tc.err != "" && err == nil:
+			t.Errorf("ParseSchedule(%q): expected an error containing %q", tc.s, tc.err)
+		case tc.err != "" && !strings.Contains(err.Error(), tc.err):
+			t.Errorf("ParseSchedule(%q): expected an error containing %q, got %v", tc.s, tc.err, err)
+		case tc.err == "" && (schedule.Days != tc.days || !reflect.DeepEqual(schedule.Windows, tc.windows)):
+			t.Errorf("ParseSchedule(%q): expected %v %+v, got %v %+v", tc.s, tc.days, tc.windows, schedule.Days, schedule.Windows)
+		}
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Wednesday March 6 2024, the clocks go forward on Sunday March 10
+	wednesday := time.Date(2024, time.March, 6, 7, 30, 0, 0, loc)
+
+	testCases := []struct {
+		schedule string
+		now      time.Time
+		until    time.Time
+		n        int
+		expected []string
+	}{
+		{
+			schedule: "weekdays 08:00-09:00 and 17:30-18:30",
+			now:      wednesday,
+			until:    wednesday.AddDate(0, 0, 7),
+			n:        10,
+			expected: []string{
+				"Wed 08:00-09:00", "Wed 17:30-18:30",
+				"Thu 08:00-09:00", "Thu 17:30-18:30",
+				"Fri 08:00-09:00", "Fri 17:30-18:30",
+				"Mon 08:00-09:00", "Mon 17:30-18:30",
+				"Tue 08:00-09:00", "Tue 17:30-18:30",
+			},
+		},
+		{
+			// the windows are in order whatever order they are given in
+			schedule: "mon-fri 17:30-18:30 and 08:00-09:00",
+			now:      wednesday,
+			until:    wednesday.AddDate(0, 0, 7),
+			n:        3,
+			expected: []string{"Wed 08:00-09:00", "Wed 17:30-18:30", "Thu 08:00-09:00"},
+		},
+		{
+			// the ongoing window starts now
+			schedule: "weekdays 08:00-09:00",
+			now:      wednesday.Add(45 * time.Minute),
+			until:    wednesday.AddDate(0, 0, 2),
+			n:        5,
+			expected: []string{"Wed 08:15-09:00", "Thu 08:00-09:00"},
+		},
+		{
+			// the forecast runs out at 07:30 on Sunday, before its window
+			schedule: "weekends 10:00-12:00",
+			now:      wednesday,
+			until:    wednesday.AddDate(0, 0, 4),
+			n:        5,
+			expected: []string{"Sat 10:00-12:00"},
+		},
+		{
+			// the window that started on Friday night is ongoing early on
+			// Saturday, and the windows start on Fridays only
+			schedule: "fri 22:00-06:00",
+			now:      time.Date(2024, time.March, 9, 2, 0, 0, 0, loc),
+			until:    time.Date(2024, time.March, 20, 0, 0, 0, 0, loc),
+			n:        5,
+			expected: []string{"Sat 02:00-06:00", "Fri 22:00-06:00"},
+		},
+		{
+			schedule: "daily 22:00-06:00",
+			now:      wednesday,
+			until:    wednesday.AddDate(0, 0, 7),
+			n:        2,
+			expected: []string{"Wed 22:00-06:00", "Thu 22:00-06:00"},
+		},
+		{
+			schedule: "weekdays 08:00-09:00",
+			now:      wednesday,
+			until:    wednesday.Add(-time.Hour),
+			n:        5,
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		schedule, err := ParseSchedule(tc.schedule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := windowTimes(loc, schedule.Occurrences(tc.now, tc.until, loc, tc.n)...)
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%s from %s: expected %q, got %q", tc.schedule, tc.now.Format("Mon 15:04"), tc.expected, got)
+		}
+	}
+
+	// the windows after the clocks go forward are at the same time of day
+	schedule, err := ParseSchedule("weekdays 08:00-09:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	occurrences := schedule.Occurrences(wednesday, wednesday.AddDate(0, 0, 7), loc, 4)
+	if d := occurrences[3][0].Sub(occurrences[2][0]); d != 3*24*time.Hour-time.Hour {
+		t.Errorf("expected the window on Monday 71 hours after Friday's, got %s", d)
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/genuinetools/weather/forecast"
 	"github.com/genuinetools/weather/geocode"
+	"github.com/genuinetools/weather/units"
 	"github.com/sirupsen/logrus"
 )
 
@@ -36,37 +38,152 @@ func (cmd *serverCommand) forecastHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// data to send to the API
-	exclude, err := json.Marshal(f.Exclude)
+	body, status, cached, err := cmd.forecast(f)
 	if err != nil {
-		writeError(w, fmt.Sprintf("marshal forecast exclude failed: %v", err))
+		writeError(w, err.Error())
 		return
 	}
-	data := url.Values{"units": {f.Units}, "exclude": {string(exclude)}}
 
-	// request the darksky.net API
-	url := fmt.Sprintf("%s/%s/%g,%g?%s", darkskyAPIURI, cmd.darkskyAPIKey, f.Latitude, f.Longitude, data.Encode())
-	resp, err := http.Get(url)
-	if err != nil {
-		writeError(w, fmt.Sprintf("request to %s failed: %v", url, err))
+	// write the response from the API to our client
+	w.Header().Set("X-Cache", "MISS")
+	if cached {
+		w.Header().Set("X-Cache", "HIT")
+	}
+	w.WriteHeader(status)
+	if _, err := w.Write(body); err != nil {
+		writeError(w, fmt.Sprintf("writing forecast failed: %v", err))
 		return
 	}
-	defer resp.Body.Close()
+}
+
+// batchResult is the forecast for one of the requests of a batch, or the
+// error getting it, like forecast.BatchResult.
+type batchResult struct {
+	Forecast json.RawMessage `json:"forecast,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// forecastBatchHandler takes a list of forecast.Request objects and passes
+// each to the darksky API, responding with the forecast for each in order,
+// so many locations take one round trip and share the cache.
+func (cmd *serverCommand) forecastBatchHandler(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	var requests []forecast.Request
+	if err := decoder.Decode(&requests); err != nil {
+		writeError(w, fmt.Sprintf("parsing request body for forecast batch failed: %v", err))
+		return
+	}
+
+	if len(requests) < 1 {
+		writeError(w, "No forecast requests were sent.")
+		return
+	}
+	if len(requests) > maxBatchRequests {
+		writeError(w, fmt.Sprintf("A batch can hold at most %d forecast requests, %d were sent.", maxBatchRequests, len(requests)))
+		return
+	}
+
+	results := make([]batchResult, len(requests))
+	each(len(requests), func(i int) {
+		body, status, _, err := cmd.forecast(requests[i])
+		switch {
+		case err != nil:
+			results[i].Error = err.Error()
+		case status != http.StatusOK:
+			results[i].Error = fmt.Sprintf("darksky.net API responded with status code: %d", status)
+		default:
+			results[i].Forecast = body
+		}
+	})
 
-	body, err := ioutil.ReadAll(resp.Body)
+	// marshal the results
+	body, err := json.Marshal(results)
 	if err != nil {
-		writeError(w, fmt.Sprintf("reading response body from %s failed: %v", url, err))
+		writeError(w, fmt.Sprintf("marshal forecast batch failed: %v", err))
 		return
 	}
 
-	// write the response from the API to our client
-	w.WriteHeader(resp.StatusCode)
+	// write the response to our client
 	if _, err := w.Write(body); err != nil {
-		writeError(w, fmt.Sprintf("writing response from %s failed: %v", url, err))
+		writeError(w, fmt.Sprintf("writing forecast batch failed: %v", err))
 		return
 	}
 }
 
+// forecast returns the response of the darksky API for the request, the
+// status of the response and whether it was cached.
+//
+// The forecast is requested from the API in the canonical units and
+// converted to the units of the request, so one cached response serves
+// every client at the location.
+func (cmd *serverCommand) forecast(f forecast.Request) ([]byte, int, bool, error) {
+	// pick the units for "auto" like the API would, by the country. Without
+	// a country, from older clients, the API still has to pick them.
+	name := f.Units
+	if (name == "" || name == "auto") && f.Country != "" {
+		name = units.ForCountry(f.Country)
+	}
+	system, convert := units.Systems[name]
+	if convert {
+		name = units.Canonical
+	}
+
+	// data to send to the API
+	data := url.Values{"units": {name}}
+	if len(f.Exclude) > 0 {
+		exclude, err := json.Marshal(f.Exclude)
+		if err != nil {
+			return nil, 0, false, fmt.Errorf("marshal forecast exclude failed: %v", err)
+		}
+		data.Set("exclude", string(exclude))
+	}
+
+	// check if we already have the forecast cached, a time makes it a
+	// time machine request for the weather on that day
+	key := fmt.Sprintf("%g,%g?%s", f.Latitude, f.Longitude, data.Encode())
+	if f.Time != 0 {
+		key = fmt.Sprintf("%g,%g,%d?%s", f.Latitude, f.Longitude, f.Time, data.Encode())
+	}
+	body, cached := cmd.cache.get(key)
+	status := http.StatusOK
+	if !cached {
+		// request the darksky.net API
+		url := fmt.Sprintf("%s/%s/%s", darkskyAPIURI, cmd.darkskyAPIKey, key)
+		resp, err := http.Get(url)
+		if err != nil {
+			return nil, 0, false, fmt.Errorf("request to %s failed: %v", url, err)
+		}
+		defer resp.Body.Close()
+
+		body, err = ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, 0, false, fmt.Errorf("reading response body from %s failed: %v", url, err)
+		}
+
+		status = resp.StatusCode
+		switch {
+		case status != http.StatusOK:
+		case f.Time != 0 && time.Unix(f.Time, 0).Before(time.Now().Add(-48*time.Hour)):
+			// the weather of days gone by does not change
+			cmd.cache.setPermanent(key, body)
+		default:
+			cmd.cache.set(key, body)
+		}
+	}
+
+	// the response is passed on as it is in the canonical units, and
+	// otherwise only the values in other units are changed
+	if status == http.StatusOK && convert {
+		b, err := forecast.ConvertJSON(body, system)
+		if err != nil {
+			return nil, 0, false, fmt.Errorf("converting forecast for %s failed: %v", key, err)
+		}
+		body = b
+	}
+
+	return body, status, cached, nil
+}
+
 // geocodeHandler takes a geocode.Request object and passes it to the Google Geocode API.
 func (cmd *serverCommand) geocodeHandler(w http.ResponseWriter, r *http.Request) {
 	decoder := json.NewDecoder(r.Body)
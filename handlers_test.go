@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testAPI serves the body for every forecast as a stand in for the darksky
+// API, and records the paths requested.
+type testAPI struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	paths []string
+}
+
+func newTestAPI(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) *testAPI {
+	api := &testAPI{}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		api.paths = append(api.paths, r.URL.Path+"?"+r.URL.RawQuery)
+		api.mu.Unlock()
+		handler(w, r)
+	}))
+
+	uri := darkskyAPIURI
+	darkskyAPIURI = api.URL
+	t.Cleanup(func() {
+		darkskyAPIURI = uri
+		api.Close()
+	})
+	return api
+}
+
+func TestForecastHandlerUnits(t *testing.T) {
+	const body = `{"latitude":40.7,"currently":{"temperature":10,"windSpeed":5},"flags":{"units":"si","sources":["cmc"]}}`
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	})
+	cmd := &serverCommand{darkskyAPIKey: "key", cache: newResponseCache(time.Minute)}
+
+	testCases := []struct {
+		request  string
+		expected string
+		cache    string
+	}{
+		// the canonical units are passed on as the API sent them
+		{
+			request:  `{"lat":40.7,"lng":-74,"units":"si"}`,
+			expected: body,
+			cache:    "MISS",
+		},
+		// other units come from the same cached response, keeping the
+		// fields a forecast does not have
+		{
+			request:  `{"lat":40.7,"lng":-74,"units":"us"}`,
+			expected: `{"currently":{"temperature":50,"windSpeed":11.18},"flags":{"sources":["cmc"],"system":{"temperature":"F","speed":"mph","distance":"mi","pressure":"mbar","precipitation":"in/h"},"units":"us"},"latitude":40.7}`,
+			cache:    "HIT",
+		},
+		{
+			request:  `{"lat":40.7,"lng":-74,"units":"auto","country":"GB"}`,
+			expected: `{"currently":{"temperature":10,"windSpeed":11.18},"flags":{"sources":["cmc"],"system":{"temperature":"C","speed":"mph","distance":"mi","pressure":"hPa","precipitation":"mm/h"},"units":"uk2"},"latitude":40.7}`,
+			cache:    "HIT",
+		},
+	}
+
+	for _, tc := range testCases {
+		w := httptest.NewRecorder()
+		cmd.forecastHandler(w, httptest.NewRequest("POST", "/forecast", strings.NewReader(tc.request)))
+		if w.Body.String() != tc.expected {
+			t.Errorf("request %s: expected\n%s\ngot\n%s", tc.request, tc.expected, w.Body.String())
+		}
+		if cache := w.Header().Get("X-Cache"); cache != tc.cache {
+			t.Errorf("request %s: expected the cache to %s, got %s", tc.request, tc.cache, cache)
+		}
+	}
+
+	if len(api.paths) != 1 || !strings.HasSuffix(api.paths[0], "units=si") {
+		t.Errorf("expected one request to the API in si units, got %q", api.paths)
+	}
+}
+
+func TestForecastBatchHandler(t *testing.T) {
+	// the API has no forecast at a latitude of 3
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		var lat, lng float64
+		fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/key/"), "%g,%g", &lat, &lng)
+		if lat == 3 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, `{"latitude":%g,"longitude":%g,"flags":{"units":"si"}}`, lat, lng)
+	})
+	cmd := &serverCommand{darkskyAPIKey: "key", cache: newResponseCache(time.Minute)}
+
+	post := func(requests interface{}) string {
+		b, err := json.Marshal(requests)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		cmd.forecastBatchHandler(w, httptest.NewRequest("POST", "/forecast/batch", bytes.NewReader(b)))
+		return w.Body.String()
+	}
+
+	// the results are in the order of the requests, whichever is answered
+	// first, with the error of each request that failed
+	var requests []map[string]interface{}
+	for _, lat := range []float64{5, 3, 1, 4, 2} {
+		requests = append(requests, map[string]interface{}{"lat": lat, "lng": 1, "units": "si"})
+	}
+	expected := `[{"forecast":{"latitude":5,"longitude":1,"flags":{"units":"si"}}},` +
+		`{"error":"darksky.net API responded with status code: 403"},` +
+		`{"forecast":{"latitude":1,"longitude":1,"flags":{"units":"si"}}},` +
+		`{"forecast":{"latitude":4,"longitude":1,"flags":{"units":"si"}}},` +
+		`{"forecast":{"latitude":2,"longitude":1,"flags":{"units":"si"}}}]`
+	if got := post(requests); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+	if len(api.paths) != 5 {
+		t.Errorf("expected a request to the API for each location, got %q", api.paths)
+	}
+
+	for _, tc := range []struct {
+		requests interface{}
+		expected string
+	}{
+		{
+			requests: make([]map[string]interface{}, maxBatchRequests+1),
+			expected: "A batch can hold at most 100 forecast requests, 101 were sent.",
+		},
+		{
+			requests: []map[string]interface{}{},
+			expected: "No forecast requests were sent.",
+		},
+		{
+			requests: map[string]interface{}{"lat": 1, "lng": 1},
+			expected: "parsing request body for forecast batch failed",
+		},
+	} {
+		var resp JSONResponse
+		if err := json.Unmarshal([]byte(post(tc.requests)), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(resp["error"], tc.expected) {
+			t.Errorf("expected the error %q, got %q", tc.expected, resp["error"])
+		}
+	}
+	if len(api.paths) != 5 {
+		t.Errorf("expected no more requests to the API for the rejected batches, got %d", len(api.paths))
+	}
+}
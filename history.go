@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/genuinetools/weather/config"
+	"github.com/genuinetools/weather/forecast"
+	"github.com/genuinetools/weather/geocode"
+)
+
+// maxHistoryDays is the most days of history that can be requested at once,
+// as each day is a request to the API.
+const maxHistoryDays = 31
+
+const historyHelp = `Show the weather on past days.
+
+The date is a day in the form 2006-01-02 or a range of days in the form
+2006-01-01..2006-01-07, of at most 31 days. The weather of days more than
+two days ago does not change, so it is cached for good. The days are those of
+the location's time zone, and cannot be after today.`
+
+func (cmd *historyCommand) Name() string      { return "history" }
+func (cmd *historyCommand) Args() string      { return "[OPTIONS]" }
+func (cmd *historyCommand) ShortHelp() string { return "Show the weather on past days." }
+func (cmd *historyCommand) LongHelp() string  { return historyHelp }
+func (cmd *historyCommand) Hidden() bool      { return false }
+
+func (cmd *historyCommand) Register(fs *flag.FlagSet) {
+	fs.StringVar(&cmd.date, "date", "", "day or range of days to show, e.g. 2024-03-02 or 2024-03-01..2024-03-07")
+	fs.StringVar(&cmd.cacheDir, "cache", filepath.Join(config.StateDir(), "history"), "directory to cache the weather of past days in")
+}
+
+type historyCommand struct {
+	date     string
+	cacheDir string
+}
+
+func (cmd *historyCommand) Run(ctx context.Context, args []string) error {
+	if cmd.date == "" {
+		return fmt.Errorf("pass the day or range of days to show with -date")
+	}
+
+	g, err := getLocation()
+	if err != nil {
+		return err
+	}
+	// the days are the location's, not the ones where weather is run
+	loc, err := locationZone(g)
+	if err != nil {
+		return err
+	}
+
+	from, to, err := parseDateRange(cmd.date, loc)
+	if err != nil {
+		return err
+	}
+	if y, m, d := time.Now().In(loc).Date(); to.After(time.Date(y, m, d, 0, 0, 0, 0, loc)) {
+		return fmt.Errorf("%s is in the future, the forecast shows the days to come", to.Format("2006-01-02"))
+	}
+
+	conf, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	var days []forecast.Forecast
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		fc, err := cmd.get(g, requestUnits(conf), date)
+		if err != nil {
+			return err
+		}
+		days = append(days, convertUnits(conf, fc))
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		for _, fc := range days {
+			if err := enc.Encode(fc); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	place := g.City
+	if g.Region != "" {
+		place += ", " + g.Region
+	}
+	opts, err := getOptions()
+	if err != nil {
+		return err
+	}
+	return forecast.PrintHistory(os.Stdout, days, place, opts)
+}
+
+// get returns the weather on the day, from the cache if it was requested
+// before.
+func (cmd *historyCommand) get(g geocode.Geocode, units string, date time.Time) (forecast.Forecast, error) {
+	// the API has the weather of the whole day for any time in it
+	noon := date.Add(12 * time.Hour)
+	name := fmt.Sprintf("%g,%g,%s,%s.json", g.Latitude, g.Longitude, date.Format("2006-01-02"), units)
+	path := filepath.Join(cmd.cacheDir, strings.Replace(name, "/", "-", -1))
+
+	var fc forecast.Forecast
+	if b, err := ioutil.ReadFile(path); err == nil {
+		if err := json.Unmarshal(b, &fc); err == nil {
+			return fc, nil
+		}
+	}
+
+	fc, err := forecast.Get(fmt.Sprintf("%s/forecast", server), forecast.Request{
+		Latitude:  g.Latitude,
+		Longitude: g.Longitude,
+		Units:     units,
+		Exclude:   []string{"minutely", "alerts"},
+		Country:   g.CountryCode,
+		Time:      noon.Unix(),
+	})
+	if err != nil {
+		return fc, err
+	}
+
+	// days still in the API's reach of corrections are not cached
+	if date.AddDate(0, 0, 3).Before(time.Now()) {
+		b, err := json.Marshal(fc)
+		if err != nil {
+			return fc, err
+		}
+		if err := os.MkdirAll(cmd.cacheDir, 0755); err != nil {
+			return fc, err
+		}
+		if err := ioutil.WriteFile(path, b, 0644); err != nil {
+			return fc, err
+		}
+	}
+	return fc, nil
+}
+
+// parseDateRange parses a day or a range of days in the form
+// "2006-01-01..2006-01-07" as midnight in loc.
+func parseDateRange(s string, loc *time.Location) (time.Time, time.Time, error) {
+	parts := strings.SplitN(s, "..", 2)
+	from, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(parts[0]), loc)
+	if err != nil {
+		return from, from, fmt.Errorf("parsing date %q failed, expected the form 2006-01-02: %v", parts[0], err)
+	}
+	to := from
+	if len(parts) == 2 {
+		to, err = time.ParseInLocation("2006-01-02", strings.TrimSpace(parts[1]), loc)
+		if err != nil {
+			return from, to, fmt.Errorf("parsing date %q failed, expected the form 2006-01-02: %v", parts[1], err)
+		}
+	}
+
+	switch {
+	case to.Before(from):
+		return from, to, fmt.Errorf("the range of days %q ends before it starts", s)
+	case to.Sub(from) >= maxHistoryDays*24*time.Hour:
+		return from, to, fmt.Errorf("the range of days %q is longer than %d days", s, maxHistoryDays)
+	}
+	return from, to, nil
+}
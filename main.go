@@ -2,18 +2,25 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/genuinetools/pkg/cli"
+	"github.com/genuinetools/weather/config"
 	"github.com/genuinetools/weather/forecast"
 	"github.com/genuinetools/weather/geocode"
+	"github.com/genuinetools/weather/units"
 	"github.com/genuinetools/weather/version"
 	"github.com/mitchellh/colorstring"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
 const (
@@ -22,19 +29,36 @@ const (
 
 var (
 	location     string
-	units        string
+	locations    locationsFlag
+	batch        []string
+	unitSystem   string
+	unitFlags    units.System
 	days         int
+	hours        int
+	showChart    bool
+	ascii        bool
+	layout       string
+	nowcast      bool
+	showChanges  bool
+	locale       string
+	clock        int
+	localTime    bool
 	ignoreAlerts bool
 	hideIcon     bool
 	noForecast   bool
 	jsonOut      bool
+	format       string
+	tmplText     string
+	tmplFile     string
 	server       string
 	client       bool
+	configPath   string
 
 	geo geocode.Geocode
 )
 
 //go:generate go run icons/generate.go
+//go:generate go run forecast/generate.go
 
 func main() {
 	// Create a new cli program.
@@ -49,30 +73,65 @@ func main() {
 	// Build the list of available commands.
 	p.Commands = []cli.Command{
 		&serverCommand{},
+		&watchCommand{},
+		&checkCommand{},
+		&notifyCommand{},
+		&publishCommand{},
+		&exporterCommand{},
+		&statusbarCommand{},
+		&sunCommand{},
+		&historyCommand{},
+		&windowCommand{},
+		&recordCommand{},
+		&trendsCommand{},
+		&accuracyCommand{},
+		&diffCommand{},
 	}
 
 	// Setup the global flags.
 	p.FlagSet = flag.NewFlagSet("global", flag.ExitOnError)
-	p.FlagSet.StringVar(&location, "location", "", "Location to get the weather")
-	p.FlagSet.StringVar(&location, "l", "", "Location to get the weather (shorthand)")
+	p.FlagSet.Var(&locations, "location", "Location to get the weather, repeat it or pass @file or - to read several from a file or stdin")
+	p.FlagSet.Var(&locations, "l", "Location to get the weather, repeat it or pass @file or - to read several from a file or stdin (shorthand)")
 
 	p.FlagSet.BoolVar(&client, "client", false, "Get location for the ssh client")
 	p.FlagSet.BoolVar(&client, "c", false, "Get location for the ssh client (shorthand)")
 
-	p.FlagSet.StringVar(&units, "units", "auto", "System of units (e.g. auto, us, si, ca, uk2)")
-	p.FlagSet.StringVar(&units, "u", "auto", "System of units (shorthand) (e.g. auto, us, si, ca, uk2)")
+	p.FlagSet.StringVar(&unitSystem, "units", "auto", "System of units (e.g. auto, us, si, ca, uk2)")
+	p.FlagSet.StringVar(&unitSystem, "u", "auto", "System of units (shorthand) (e.g. auto, us, si, ca, uk2)")
 
 	p.FlagSet.StringVar(&server, "server", defaultServerURI, "Weather API server uri")
 	p.FlagSet.StringVar(&server, "s", defaultServerURI, "Weather API server uri (shorthand)")
 
+	p.FlagSet.StringVar(&unitFlags.Temperature, "temperature-unit", "", "Unit of temperature, overriding the system of units (C, F)")
+	p.FlagSet.StringVar(&unitFlags.Speed, "speed-unit", "", "Unit of speed, overriding the system of units (kn, km/h, m/s, mph)")
+	p.FlagSet.StringVar(&unitFlags.Distance, "distance-unit", "", "Unit of distance, overriding the system of units (km, mi)")
+	p.FlagSet.StringVar(&unitFlags.Pressure, "pressure-unit", "", "Unit of pressure, overriding the system of units (hPa, inHg, mbar, mmHg)")
+	p.FlagSet.StringVar(&unitFlags.Precipitation, "precip-unit", "", "Unit of precipitation intensity, overriding the system of units (in/h, mm/h)")
+
 	p.FlagSet.IntVar(&days, "days", 0, "No. of days to get forecast")
 	p.FlagSet.IntVar(&days, "d", 0, "No. of days to get forecast (shorthand)")
 
+	p.FlagSet.IntVar(&hours, "hours", 0, fmt.Sprintf("No. of hours of hourly forecast to show in a table (max %d)", forecast.MaxHours))
+	p.FlagSet.BoolVar(&showChart, "chart", false, "Show charts of the hourly temperature and precipitation, and the daily highs and lows")
+	p.FlagSet.BoolVar(&ascii, "ascii", false, "Draw charts with plain ascii characters")
+	p.FlagSet.StringVar(&locale, "locale", defaultLocale(), "Locale to write dates in ("+strings.Join(forecast.LocaleNames(), ", ")+")")
+	p.FlagSet.IntVar(&clock, "clock", 0, "Use a 12 or 24-hour clock, defaults to the locale's")
+	p.FlagSet.BoolVar(&localTime, "local-time", false, "Also show times in the local time zone when it differs from the location's")
+
+	p.FlagSet.BoolVar(&nowcast, "nowcast", false, "Show the minute by minute precipitation for the next hour")
+	p.FlagSet.BoolVar(&showChanges, "changes", false, "Show what changed in the hourly and daily forecast since the last run")
+	p.FlagSet.StringVar(&layout, "layout", "", "Layout of the daily forecast ("+strings.Join(forecast.Layouts, ", ")+"), defaults to grid on a wide terminal")
+
 	p.FlagSet.BoolVar(&ignoreAlerts, "ignore-alerts", false, "Ignore alerts in weather output")
 	p.FlagSet.BoolVar(&hideIcon, "hide-icon", false, "Hide the weather icons from being output")
 	p.FlagSet.BoolVar(&noForecast, "no-forecast", false, "Hide the forecast for the next 16 hours")
 
 	p.FlagSet.BoolVar(&jsonOut, "json", false, "Prints the raw JSON API response")
+	p.FlagSet.StringVar(&format, "format", "text", "Output format ("+strings.Join(forecast.RendererNames(), ", ")+")")
+	p.FlagSet.StringVar(&tmplText, "template", "", "Go template to format the output with, e.g. '{{.Currently.Temperature}}{{.Units.Degrees}}'")
+	p.FlagSet.StringVar(&tmplFile, "template-file", "", "Path to a file with a Go template to format the output with")
+
+	p.FlagSet.StringVar(&configPath, "config", config.Path(), "Path to the config file with saved locations and webhooks")
 
 	// Set the before function.
 	p.Before = func(ctx context.Context) error {
@@ -80,76 +139,127 @@ func main() {
 			return errors.New("please enter a weather API server uri or leave blank to use the default")
 		}
 
+		if jsonOut {
+			format = "json"
+		}
+
+		names, err := readLocations(locations)
+		if err != nil {
+			return err
+		}
+		switch len(names) {
+		case 0:
+		case 1:
+			location = names[0]
+		default:
+			batch = names
+		}
+		r, err := getRenderer()
+		if err != nil {
+			return err
+		}
+		if _, ok := r.(forecast.TextRenderer); len(batch) > 0 && !ok && format != "json" {
+			return errors.New("several locations can only be shown as a table or as JSON")
+		}
+		if _, ok := r.(forecast.TextRenderer); showChanges && !ok {
+			return errors.New("the changes to the forecast can only be shown in the text output")
+		}
+
+		switch layout {
+		case "":
+			layout = forecast.LayoutProse
+			if terminalWidth() >= forecast.GridMinWidth {
+				layout = forecast.LayoutGrid
+			}
+		case forecast.LayoutGrid, forecast.LayoutProse:
+		default:
+			return fmt.Errorf("unknown layout %q, expected one of: %s", layout, strings.Join(forecast.Layouts, ", "))
+		}
+
+		if err := unitFlags.Validate(); err != nil {
+			return err
+		}
+
+		if _, ok := forecast.Locales[locale]; !ok {
+			return fmt.Errorf("unknown locale %q, expected one of: %s", locale, strings.Join(forecast.LocaleNames(), ", "))
+		}
+		if clock != 0 && clock != 12 && clock != 24 {
+			return fmt.Errorf("the clock must be 12 or 24, got %d", clock)
+		}
+
+		if (hours > 0 || showChart) && noForecast {
+			return errors.New("the hourly forecast cannot be shown with -no-forecast")
+		}
+
 		return nil
 	}
 
 	// Set the main program action.
 	p.Action = func(ctx context.Context, args []string) error {
-		var err error
-		if location == "" {
-			sshConn := os.Getenv("SSH_CONNECTION")
-			if client && len(sshConn) > 0 {
-				// use their ssh connection to locate them
-				ipports := strings.Split(sshConn, " ")
-				geo, err = geocode.IPLocate(ipports[0])
-				if err != nil {
-					printError(err)
-				}
-
-			} else {
-				// auto locate them
-				geo, err = geocode.Autolocate()
-				if err != nil {
-					printError(err)
-				}
-
-				if geo.Latitude == 0 || geo.Longitude == 0 {
-					printError(errors.New("latitude and longitude could not be determined from your IP so the weather will not be accurate\nTry: weather -l <your_zipcode> OR weather -l \"your city, state\""))
-				}
-			}
-		} else {
-			// get geolocation data for the given location
-			geo, err = geocode.Locate(location, server)
+		// a time to show the weather at, e.g. "tomorrow" or "saturday 3pm"
+		var when *forecast.When
+		if len(args) > 0 {
+			w, err := forecast.ParseWhen(strings.Join(args, " "))
 			if err != nil {
 				printError(err)
 			}
+			when = &w
 		}
 
-		if geo.Latitude == 0 || geo.Longitude == 0 {
-			printError(errors.New("latitude and longitude could not be determined so the weather will not be accurate"))
+		if len(batch) > 0 {
+			if when != nil {
+				printError(errors.New("the weather at a time cannot be shown for several locations"))
+			}
+			if err := renderBatch(os.Stdout, batch); err != nil {
+				printError(err)
+			}
+			return nil
 		}
 
-		data := forecast.Request{
-			Latitude:  geo.Latitude,
-			Longitude: geo.Longitude,
-			Units:     units,
-			Exclude:   []string{"minutely"},
-		}
-		if noForecast {
-			data.Exclude = append(data.Exclude, "hourly")
+		var err error
+		geo, err = getLocation()
+		if err != nil {
+			printError(err)
 		}
 
-		fc, err := forecast.Get(fmt.Sprintf("%s/forecast", server), data)
+		fc, err := getForecast(geo)
 		if err != nil {
 			printError(err)
 		}
 
-		if jsonOut {
-			jsn, err := json.Marshal(&fc)
+		// the forecast is kept to show what changed in it on the next run
+		previous, kept, err := loadLastForecast(geo)
+		if err != nil {
+			logrus.Warnf("loading the last forecast failed: %v", err)
+		}
+		if err := saveLastForecast(geo, fc); err != nil {
+			logrus.Warnf("keeping the forecast failed: %v", err)
+		}
+
+		if when != nil {
+			moment, err := when.Find(fc)
 			if err != nil {
 				printError(err)
 			}
-
-			fmt.Println(string(jsn))
+			if err := renderMoment(os.Stdout, fc, moment, geo); err != nil {
+				printError(err)
+			}
 			return nil
 		}
 
-		if err := forecast.PrintCurrent(fc, geo, ignoreAlerts, hideIcon); err != nil {
+		if err := render(os.Stdout, fc, geo); err != nil {
 			printError(err)
 		}
 
-		if days > 0 {
-			forecast.PrintDaily(fc, days)
+		if showChanges && kept {
+			revisions, err := getRevisions(previous, fc)
+			if err != nil {
+				printError(err)
+			}
+			fmt.Println()
+			if err := renderRevisions(os.Stdout, previous, revisions); err != nil {
+				printError(err)
+			}
 		}
 
 		return nil
@@ -159,6 +269,321 @@ func main() {
 	p.Run()
 }
 
+// getLocation returns the geocode data for the location passed via the flags,
+// or for the ssh client or the current machine when no location was given.
+func getLocation() (geocode.Geocode, error) {
+	var (
+		g   geocode.Geocode
+		err error
+	)
+	if len(batch) > 0 {
+		return g, errors.New("several locations can only be passed to get the weather, not to this command")
+	}
+
+	sshConn := os.Getenv("SSH_CONNECTION")
+	switch {
+	case location != "":
+		// get geolocation data for the given location
+		g, err = geocode.Locate(location, server)
+		if err != nil {
+			return g, err
+		}
+	case client && len(sshConn) > 0:
+		// use their ssh connection to locate them
+		ipports := strings.Split(sshConn, " ")
+		g, err = geocode.IPLocate(ipports[0])
+		if err != nil {
+			return g, err
+		}
+	default:
+		// auto locate them
+		g, err = geocode.Autolocate()
+		if err != nil {
+			return g, err
+		}
+
+		if g.Latitude == 0 || g.Longitude == 0 {
+			return g, errors.New("latitude and longitude could not be determined from your IP so the weather will not be accurate\nTry: weather -l <your_zipcode> OR weather -l \"your city, state\"")
+		}
+	}
+
+	if g.Latitude == 0 || g.Longitude == 0 {
+		return g, errors.New("latitude and longitude could not be determined so the weather will not be accurate")
+	}
+
+	return g, nil
+}
+
+// locateSaved returns the geocode data for a location saved in the config,
+// geocodes holds the locations that were already looked up.
+func locateSaved(conf config.Config, geocodes map[string]geocode.Geocode, name string) (geocode.Geocode, error) {
+	if g, ok := geocodes[name]; ok {
+		return g, nil
+	}
+
+	query, ok := conf.Locations[name]
+	if !ok {
+		return geocode.Geocode{}, fmt.Errorf("location %q is not saved in the config file %s", name, configPath)
+	}
+
+	g, err := geocode.Locate(query, server)
+	if err != nil {
+		return g, err
+	}
+	if g.Latitude == 0 || g.Longitude == 0 {
+		return g, fmt.Errorf("latitude and longitude could not be determined for %q", query)
+	}
+
+	geocodes[name] = g
+	return g, nil
+}
+
+// getForecast requests the forecast for the geocode from the weather API
+// server using the units and exclusions passed via the flags, along with
+// any extra blocks to exclude. The units set in the config and the flags for
+// each quantity are converted to after.
+func getForecast(g geocode.Geocode, exclude ...string) (forecast.Forecast, error) {
+	conf, err := loadConfig()
+	if err != nil {
+		return forecast.Forecast{}, err
+	}
+
+	fc, err := forecast.Get(fmt.Sprintf("%s/forecast", server), forecastRequest(conf, g, exclude...))
+	if err != nil {
+		return fc, err
+	}
+	return convertUnits(conf, fc), nil
+}
+
+// locationZone returns the time zone of the location, from the geocode or
+// else from the forecast for it.
+func locationZone(g geocode.Geocode) (*time.Location, error) {
+	if g.Timezone != "" {
+		if loc, err := time.LoadLocation(g.Timezone); err == nil {
+			return loc, nil
+		}
+	}
+
+	fc, err := getForecast(g, "minutely", "hourly", "daily", "alerts")
+	if err != nil {
+		return nil, err
+	}
+	return fc.Location(), nil
+}
+
+// forecastRequest returns the request for the forecast for the geocode
+// using the units and exclusions passed via the flags, along with any extra
+// blocks to exclude. The minutely block is excluded unless the nowcast is
+// shown.
+func forecastRequest(conf config.Config, g geocode.Geocode, exclude ...string) forecast.Request {
+	data := forecast.Request{
+		Latitude:  g.Latitude,
+		Longitude: g.Longitude,
+		Units:     requestUnits(conf),
+		Exclude:   exclude,
+		Country:   g.CountryCode,
+	}
+	if noForecast && !excludes(data.Exclude, "hourly") {
+		data.Exclude = append(data.Exclude, "hourly")
+	}
+	// the minute by minute forecast is only downloaded for the nowcast
+	if !nowcast && !excludes(data.Exclude, "minutely") {
+		data.Exclude = append(data.Exclude, "minutely")
+	}
+	return data
+}
+
+// excludes returns whether the block is in the exclusions.
+func excludes(exclude []string, block string) bool {
+	for _, e := range exclude {
+		if e == block {
+			return true
+		}
+	}
+	return false
+}
+
+// requestUnits returns the system of units to request the forecast in,
+// the one set in the config unless the flags pass one.
+func requestUnits(conf config.Config) string {
+	if unitSystem == "auto" && conf.Units.Name != "" {
+		return conf.Units.Name
+	}
+	return unitSystem
+}
+
+// convertUnits converts the forecast to the units of each quantity set in
+// the config or the flags.
+func convertUnits(conf config.Config, fc forecast.Forecast) forecast.Forecast {
+	overrides := conf.Units.System.Override(unitFlags)
+	if overrides == (units.System{}) {
+		return fc
+	}
+	return fc.Convert(fc.System().Override(overrides))
+}
+
+// displayUnits returns the system of units to show recorded weather in, as
+// it has no location to pick them by the country with for "auto".
+func displayUnits(conf config.Config) units.System {
+	system, ok := units.Systems[requestUnits(conf)]
+	if !ok {
+		system = units.Systems[units.Canonical]
+	}
+	return system.Override(conf.Units.System.Override(unitFlags))
+}
+
+// getRenderer returns the renderer for the format or template passed via
+// the flags.
+func getRenderer() (forecast.Renderer, error) {
+	if tmplFile != "" {
+		b, err := ioutil.ReadFile(tmplFile)
+		if err != nil {
+			return nil, err
+		}
+		tmplText = string(b)
+	}
+
+	if tmplText != "" {
+		return forecast.NewTemplateRenderer(tmplText)
+	}
+
+	return forecast.GetRenderer(format)
+}
+
+// render writes the forecast in the format passed via the flags.
+func render(w io.Writer, fc forecast.Forecast, g geocode.Geocode) error {
+	r, err := getRenderer()
+	if err != nil {
+		return err
+	}
+	opts, err := getOptions()
+	if err != nil {
+		return err
+	}
+
+	return r.Render(w, fc, g, opts)
+}
+
+// renderMoment writes the weather at the moment, in the layout of the
+// current weather for text or as the forecast from then on otherwise.
+func renderMoment(w io.Writer, fc forecast.Forecast, moment forecast.Moment, g geocode.Geocode) error {
+	r, err := getRenderer()
+	if err != nil {
+		return err
+	}
+	opts, err := getOptions()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := r.(forecast.TextRenderer); ok {
+		return forecast.PrintMoment(w, fc, moment, g, opts)
+	}
+	return r.Render(w, moment.Forecast(fc), g, opts)
+}
+
+// getOptions returns the options of the output passed via the flags and
+// set in the config.
+func getOptions() (forecast.Options, error) {
+	conf, err := loadConfig()
+	if err != nil {
+		return forecast.Options{}, err
+	}
+	rules, window, err := getAdvice(conf)
+	if err != nil {
+		return forecast.Options{}, err
+	}
+
+	return forecast.Options{
+		IgnoreAlerts: ignoreAlerts,
+		HideIcon:     hideIcon,
+		Days:         days,
+		Hours:        hours,
+		Width:        terminalWidth(),
+		Chart:        showChart,
+		ASCII:        ascii,
+		Layout:       layout,
+		Nowcast:      nowcast,
+		Locale:       locale,
+		Clock:        clock,
+		LocalTime:    localTime,
+		Comfort:      conf.Comfort,
+		Rules:        rules,
+		Window:       window,
+	}, nil
+}
+
+// getAdvice returns the rules and window of what to wear and bring set in
+// the config, the rules in the file added to the default rules.
+func getAdvice(conf config.Config) ([]forecast.Rule, forecast.Window, error) {
+	var window forecast.Window
+	if conf.Advice.Window != "" {
+		w, err := forecast.ParseWindow(conf.Advice.Window)
+		if err != nil {
+			return nil, window, err
+		}
+		window = w
+	}
+
+	if conf.Advice.Rules == "" {
+		return forecast.DefaultRules, window, nil
+	}
+	path := conf.Advice.Rules
+	if strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		path = filepath.Join(home, path[2:])
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, window, err
+	}
+	rules, err := forecast.ParseRules(b)
+	if err != nil {
+		return nil, window, fmt.Errorf("%s: %v", path, err)
+	}
+	return append(append([]forecast.Rule{}, forecast.DefaultRules...), rules...), window, nil
+}
+
+// terminalWidth returns the width of the terminal stdout is attached to,
+// or zero if it is not a terminal.
+func terminalWidth() int {
+	width, _, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// defaultLocale returns the locale from the environment, e.g. de-DE for
+// LANG=de_DE.UTF-8, matching on the language alone if the region is not
+// one of the locales.
+func defaultLocale() string {
+	for _, env := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+		v := os.Getenv(env)
+		if v == "" {
+			continue
+		}
+
+		v = strings.Replace(strings.SplitN(v, ".", 2)[0], "_", "-", 1)
+		if _, ok := forecast.Locales[v]; ok {
+			return v
+		}
+		language := strings.SplitN(v, "-", 2)[0] + "-"
+		for _, name := range forecast.LocaleNames() {
+			if strings.HasPrefix(name, language) {
+				return name
+			}
+		}
+		break
+	}
+	return forecast.DefaultLocale
+}
+
+// loadConfig reads the config file passed via the flags.
+func loadConfig() (config.Config, error) {
+	return config.Load(configPath)
+}
+
 func printError(err error) {
 	fmt.Println(colorstring.Color("[red]" + err.Error()))
 	os.Exit(1)
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/genuinetools/weather/config"
+	"github.com/genuinetools/weather/geocode"
+)
+
+func TestForecastRequestExclude(t *testing.T) {
+	defer func(n, f bool) { nowcast, noForecast = n, f }(nowcast, noForecast)
+
+	testCases := []struct {
+		nowcast, noForecast bool
+		exclude             []string
+		expected            []string
+	}{
+		{expected: []string{"minutely"}},
+		{nowcast: true, expected: nil},
+		{exclude: []string{"daily"}, expected: []string{"daily", "minutely"}},
+		// the callers that never show the nowcast exclude it themselves
+		{nowcast: true, exclude: []string{"minutely", "daily"}, expected: []string{"minutely", "daily"}},
+		{exclude: []string{"minutely", "daily"}, expected: []string{"minutely", "daily"}},
+		{noForecast: true, expected: []string{"hourly", "minutely"}},
+		{noForecast: true, exclude: []string{"hourly"}, expected: []string{"hourly", "minutely"}},
+	}
+
+	for _, tc := range testCases {
+		nowcast, noForecast = tc.nowcast, tc.noForecast
+		data := forecastRequest(config.Config{}, geocode.Geocode{}, tc.exclude...)
+		if !reflect.DeepEqual(data.Exclude, tc.expected) {
+			t.Errorf("nowcast %t, no forecast %t, exclude %q: expected %q, got %q", tc.nowcast, tc.noForecast, tc.exclude, tc.expected, data.Exclude)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package mqtt
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"time"
+)
+
+// MQTT 3.1.1 control packet types.
+const (
+	packetConnect    byte = 1 << 4
+	packetConnack    byte = 2 << 4
+	packetPublish    byte = 3 << 4
+	packetDisconnect byte = 14 << 4
+)
+
+// connackErrors are the CONNACK return codes.
+var connackErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// Options describe how to connect to the broker.
+type Options struct {
+	// Broker is the uri of the broker, e.g. tcp://localhost:1883 or
+	// ssl://broker:8883.
+	Broker   string
+	ClientID string
+	Username string
+	Password string
+	Timeout  time.Duration
+}
+
+// Client is a minimal MQTT 3.1.1 client that can only publish messages
+// with QoS 0.
+type Client struct {
+	conn net.Conn
+	w    *bufio.Writer
+}
+
+// Connect connects to the broker and waits for it to accept the connection.
+func Connect(opts Options) (*Client, error) {
+	if opts.Password != "" && opts.Username == "" {
+		return nil, fmt.Errorf("a password needs a username in MQTT 3.1.1")
+	}
+
+	u, err := url.Parse(opts.Broker)
+	if err != nil {
+		return nil, fmt.Errorf("parsing broker uri %s failed: %v", opts.Broker, err)
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = 30 * time.Second
+	}
+
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+	var conn net.Conn
+	switch u.Scheme {
+	case "tcp", "mqtt":
+		conn, err = dialer.Dial("tcp", hostPort(u, "1883"))
+	case "ssl", "tls", "mqtts":
+		conn, err = tls.DialWithDialer(dialer, "tcp", hostPort(u, "8883"), &tls.Config{ServerName: u.Hostname()})
+	default:
+		return nil, fmt.Errorf("unsupported broker scheme %q, expected tcp or ssl", u.Scheme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connecting to broker %s failed: %v", opts.Broker, err)
+	}
+
+	c := &Client{conn: conn, w: bufio.NewWriter(conn)}
+	conn.SetDeadline(time.Now().Add(opts.Timeout))
+	if err := c.connect(opts); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+
+	return c, nil
+}
+
+// Publish sends a message to the topic.
+func (c *Client) Publish(topic string, payload []byte, retain bool) error {
+	header := packetPublish
+	if retain {
+		header |= 0x01
+	}
+
+	body := appendString(nil, topic)
+	body = append(body, payload...)
+	return c.write(header, body)
+}
+
+// Close disconnects from the broker.
+func (c *Client) Close() error {
+	err := c.write(packetDisconnect, nil)
+	if cerr := c.conn.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (c *Client) connect(opts Options) error {
+	flags := byte(0x02) // clean session
+	if opts.Username != "" {
+		flags |= 0x80
+	}
+	if opts.Password != "" {
+		flags |= 0x40
+	}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, flags, 0, 60) // protocol level 4, keep alive 60s
+	body = appendString(body, opts.ClientID)
+	if opts.Username != "" {
+		body = appendString(body, opts.Username)
+	}
+	if opts.Password != "" {
+		body = appendString(body, opts.Password)
+	}
+	if err := c.write(packetConnect, body); err != nil {
+		return err
+	}
+
+	// read the CONNACK
+	ack := make([]byte, 4)
+	if _, err := io.ReadFull(c.conn, ack); err != nil {
+		return fmt.Errorf("reading connack from broker failed: %v", err)
+	}
+	if ack[0] != packetConnack || ack[1] != 2 {
+		return errors.New("broker did not reply with a connack")
+	}
+	if ack[3] != 0 {
+		msg, ok := connackErrors[ack[3]]
+		if !ok {
+			msg = fmt.Sprintf("return code %d", ack[3])
+		}
+		return fmt.Errorf("broker refused the connection: %s", msg)
+	}
+
+	return nil
+}
+
+// write sends a control packet with the fixed header and body.
+func (c *Client) write(header byte, body []byte) error {
+	if err := c.w.WriteByte(header); err != nil {
+		return err
+	}
+
+	// remaining length is encoded 7 bits at a time
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		if err := c.w.WriteByte(b); err != nil {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+	}
+
+	if _, err := c.w.Write(body); err != nil {
+		return err
+	}
+	return c.w.Flush()
+}
+
+// appendString appends a length prefixed UTF-8 string.
+func appendString(b []byte, s string) []byte {
+	b = append(b, byte(len(s)>>8), byte(len(s)))
+	return append(b, s...)
+}
+
+func hostPort(u *url.URL, port string) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
@@ -0,0 +1,233 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// packet is a control packet read by the test broker.
+type packet struct {
+	header byte
+	body   []byte
+}
+
+// readPacket reads a control packet, decoding the remaining length.
+func readPacket(r *bufio.Reader) (packet, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return packet{}, err
+	}
+
+	n, multiplier := 0, 1
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return packet{}, err
+		}
+		n += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return packet{}, err
+	}
+	return packet{header: header, body: body}, nil
+}
+
+// readString reads a length prefixed string off the front of b.
+func readString(t *testing.T, b []byte) (string, []byte) {
+	if len(b) < 2 {
+		t.Fatalf("expected a length prefixed string, got %v", b)
+	}
+	n := int(b[0])<<8 | int(b[1])
+	if len(b) < 2+n {
+		t.Fatalf("string of length %d is longer than the %d bytes left", n, len(b)-2)
+	}
+	return string(b[2 : 2+n]), b[2+n:]
+}
+
+// broker is an in-process broker that accepts a single connection, replies
+// to the CONNECT with the return code and collects the packets it got.
+func broker(t *testing.T, code byte) (string, <-chan []packet) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packets := make(chan []packet, 1)
+	go func() {
+		defer l.Close()
+		var got []packet
+		defer func() { packets <- got }()
+
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		r := bufio.NewReader(conn)
+		for {
+			p, err := readPacket(r)
+			if err != nil {
+				return
+			}
+			got = append(got, p)
+			if p.header == packetConnect {
+				conn.Write([]byte{packetConnack, 2, 0, code})
+			}
+			if p.header == packetDisconnect {
+				return
+			}
+		}
+	}()
+
+	return "tcp://" + l.Addr().String(), packets
+}
+
+func TestPublish(t *testing.T) {
+	uri, packets := broker(t, 0)
+
+	c, err := Connect(Options{
+		Broker:   uri,
+		ClientID: "weather-1",
+		Username: "user",
+		Password: "secret",
+		Timeout:  5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// payloads around the one, two and three byte remaining lengths
+	payloads := [][]byte{
+		[]byte("21.5"),
+		bytes.Repeat([]byte("a"), 127-len("weather/home/summary")-2),
+		bytes.Repeat([]byte("b"), 300),
+		bytes.Repeat([]byte("c"), 20000),
+	}
+	for i, payload := range payloads {
+		if err := c.Publish("weather/home/summary", payload, i%2 == 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := <-packets
+	if len(got) != len(payloads)+2 {
+		t.Fatalf("expected a connect, %d publishes and a disconnect, got %d packets", len(payloads), len(got))
+	}
+
+	connect := got[0]
+	if connect.header != packetConnect {
+		t.Fatalf("expected a connect packet first, got header %#x", connect.header)
+	}
+	protocol, body := readString(t, connect.body)
+	if protocol != "MQTT" {
+		t.Errorf("expected protocol name MQTT, got %q", protocol)
+	}
+	if len(body) < 4 {
+		t.Fatalf("connect variable header is too short: %v", body)
+	}
+	if level := body[0]; level != 4 {
+		t.Errorf("expected protocol level 4, got %d", level)
+	}
+	if flags := body[1]; flags != 0x80|0x40|0x02 {
+		t.Errorf("expected the user name, password and clean session flags, got %#x", flags)
+	}
+	if keepAlive := int(body[2])<<8 | int(body[3]); keepAlive != 60 {
+		t.Errorf("expected a keep alive of 60s, got %d", keepAlive)
+	}
+	body = body[4:]
+	for _, expected := range []string{"weather-1", "user", "secret"} {
+		var s string
+		s, body = readString(t, body)
+		if s != expected {
+			t.Errorf("expected %q in the connect payload, got %q", expected, s)
+		}
+	}
+	if len(body) != 0 {
+		t.Errorf("expected nothing after the password, got %d bytes", len(body))
+	}
+
+	for i, payload := range payloads {
+		p := got[i+1]
+		retain := byte(0)
+		if i%2 == 0 {
+			retain = 0x01
+		}
+		if p.header != packetPublish|retain {
+			t.Errorf("publish %d: expected header %#x, got %#x", i, packetPublish|retain, p.header)
+		}
+		topic, rest := readString(t, p.body)
+		if topic != "weather/home/summary" {
+			t.Errorf("publish %d: expected topic weather/home/summary, got %q", i, topic)
+		}
+		if !bytes.Equal(rest, payload) {
+			t.Errorf("publish %d: expected a payload of %d bytes, got %d", i, len(payload), len(rest))
+		}
+	}
+
+	if last := got[len(got)-1]; last.header != packetDisconnect || len(last.body) != 0 {
+		t.Errorf("expected an empty disconnect packet last, got header %#x with %d bytes", last.header, len(last.body))
+	}
+}
+
+func TestRemainingLength(t *testing.T) {
+	testCases := []struct {
+		n        int
+		expected []byte
+	}{
+		{n: 0, expected: []byte{0x00}},
+		{n: 127, expected: []byte{0x7f}},
+		{n: 128, expected: []byte{0x80, 0x01}},
+		{n: 321, expected: []byte{0xc1, 0x02}},
+		{n: 16383, expected: []byte{0xff, 0x7f}},
+		{n: 16384, expected: []byte{0x80, 0x80, 0x01}},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		c := &Client{w: bufio.NewWriter(&buf)}
+		if err := c.write(packetPublish, make([]byte, tc.n)); err != nil {
+			t.Fatal(err)
+		}
+		header := buf.Bytes()[1 : 1+len(tc.expected)]
+		if !bytes.Equal(header, tc.expected) {
+			t.Errorf("remaining length %d: expected %#v, got %#v", tc.n, tc.expected, header)
+		}
+		if buf.Len() != 1+len(tc.expected)+tc.n {
+			t.Errorf("remaining length %d: expected a packet of %d bytes, got %d", tc.n, 1+len(tc.expected)+tc.n, buf.Len())
+		}
+	}
+}
+
+func TestConnectRefused(t *testing.T) {
+	uri, packets := broker(t, 4)
+
+	_, err := Connect(Options{Broker: uri, ClientID: "weather-1", Timeout: 5 * time.Second})
+	if err == nil || !strings.Contains(err.Error(), "bad user name or password") {
+		t.Errorf("expected the connection to be refused, got %v", err)
+	}
+	<-packets
+}
+
+func TestConnectPasswordWithoutUsername(t *testing.T) {
+	// the broker is not dialed
+	_, err := Connect(Options{Broker: "tcp://127.0.0.1:1", ClientID: "weather-1", Password: "secret"})
+	if err == nil || !strings.Contains(err.Error(), "a password needs a username") {
+		t.Errorf("expected an error for the password without a username, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/genuinetools/weather/config"
+	"github.com/genuinetools/weather/geocode"
+	"github.com/genuinetools/weather/notify"
+	"github.com/sirupsen/logrus"
+)
+
+const notifyHelp = `Post weather alerts for the saved locations to webhooks.
+
+Locations and webhooks are read from the config file. A notification is sent
+when an alert is new or updated and again once it has expired. What was sent
+is tracked per webhook, so one that is down is sent what it missed later.`
+
+func (cmd *notifyCommand) Name() string      { return "notify" }
+func (cmd *notifyCommand) Args() string      { return "[OPTIONS]" }
+func (cmd *notifyCommand) ShortHelp() string { return "Post weather alerts to webhooks." }
+func (cmd *notifyCommand) LongHelp() string  { return notifyHelp }
+func (cmd *notifyCommand) Hidden() bool      { return false }
+
+func (cmd *notifyCommand) Register(fs *flag.FlagSet) {
+	fs.DurationVar(&cmd.interval, "interval", 5*time.Minute, "how often to check for alerts")
+	fs.BoolVar(&cmd.once, "once", false, "check for alerts once and exit")
+	fs.StringVar(&cmd.statePath, "state", filepath.Join(config.StateDir(), "alerts.json"), "path to the file that tracks the alerts already sent")
+}
+
+type notifyCommand struct {
+	interval  time.Duration
+	once      bool
+	statePath string
+
+	geocodes map[string]geocode.Geocode
+}
+
+func (cmd *notifyCommand) Run(ctx context.Context, args []string) error {
+	if !cmd.once && cmd.interval < minWatchInterval {
+		return fmt.Errorf("interval must be at least %s", minWatchInterval)
+	}
+
+	conf, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if len(conf.Locations) < 1 {
+		return fmt.Errorf("no locations saved in the config file %s", configPath)
+	}
+	if len(conf.Webhooks) < 1 {
+		return fmt.Errorf("no webhooks saved in the config file %s", configPath)
+	}
+	for _, webhook := range conf.Webhooks {
+		if _, err := notify.Payload(webhook.Format, notify.Event{}); err != nil {
+			return err
+		}
+	}
+
+	state, err := notify.LoadState(cmd.statePath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withSignals(ctx)
+	defer cancel()
+
+	cmd.geocodes = map[string]geocode.Geocode{}
+	ticker := time.NewTicker(cmd.interval)
+	defer ticker.Stop()
+	for {
+		for _, name := range conf.LocationNames() {
+			if err := cmd.check(conf, state, name); err != nil {
+				logrus.Warnf("checking alerts for %s failed: %v", name, err)
+			}
+		}
+
+		if err := state.Save(cmd.statePath); err != nil {
+			return err
+		}
+
+		if cmd.once {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// check sends notifications for the alerts of a saved location that changed
+// since they were last sent.
+func (cmd *notifyCommand) check(conf config.Config, state *notify.State, name string) error {
+	g, err := locateSaved(conf, cmd.geocodes, name)
+	if err != nil {
+		return err
+	}
+
+	fc, err := getForecast(g, "currently", "minutely", "hourly", "daily")
+	if err != nil {
+		return err
+	}
+
+	// each webhook is tracked on its own, so an event that failed to send
+	// to one is retried for it alone
+	now := time.Now().Unix()
+	for _, webhook := range conf.Webhooks {
+		for _, event := range state.Diff(name, webhook, fc.Alerts, now) {
+			event.Zone = fc.Location()
+			if err := notify.Send(webhook, event); err != nil {
+				logrus.Warnf("sending %s alert %q for %s failed: %v", event.Kind, event.Alert.Title, name, err)
+				continue
+			}
+			logrus.Infof("sent %s alert %q for %s", event.Kind, event.Alert.Title, name)
+			state.Record(webhook, event)
+		}
+	}
+
+	return nil
+}
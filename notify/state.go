@@ -0,0 +1,159 @@
+package notify
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/genuinetools/weather/config"
+	"github.com/genuinetools/weather/forecast"
+)
+
+// Event kinds.
+const (
+	EventNew     = "new"
+	EventUpdated = "updated"
+	EventExpired = "expired"
+)
+
+// Event is a change to the alerts for a location that should be sent.
+type Event struct {
+	Kind     string         `json:"event"`
+	Location string         `json:"location"`
+	Alert    forecast.Alert `json:"alert"`
+
+	// Zone is the time zone of the location the times of the alert are
+	// written in, local time if it is nil.
+	Zone *time.Location `json:"-"`
+}
+
+// State holds the alerts that notifications were already sent for,
+// keyed by location, webhook and alert, so a webhook that is down gets
+// the alerts once it is back without the others getting them again.
+type State struct {
+	Alerts map[string]Sent `json:"alerts"`
+}
+
+// Sent is an alert a notification was sent for to a webhook, by the ID of
+// the webhook.
+type Sent struct {
+	Location string         `json:"location"`
+	Webhook  string         `json:"webhook"`
+	Alert    forecast.Alert `json:"alert"`
+}
+
+// LoadState reads the state file at path. A missing file returns an
+// empty state.
+func LoadState(path string) (*State, error) {
+	s := &State{Alerts: map[string]Sent{}}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("decoding state file %s failed: %v", path, err)
+	}
+	if s.Alerts == nil {
+		s.Alerts = map[string]Sent{}
+	}
+
+	return s, nil
+}
+
+// Save writes the state file to path.
+func (s *State) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so we never leave a partial state file
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Diff returns the events for the current alerts of a location compared
+// to what was already sent to the webhook. Alerts that are past their
+// expiry at now are treated as gone.
+func (s *State) Diff(location string, webhook config.Webhook, alerts []forecast.Alert, now int64) []Event {
+	events := []Event{}
+	id := webhookID(webhook)
+
+	current := map[string]bool{}
+	for _, alert := range alerts {
+		if alert.Expires > 0 && alert.Expires <= now {
+			continue
+		}
+
+		key := stateKey(location, id, alert)
+		current[key] = true
+
+		sent, ok := s.Alerts[key]
+		switch {
+		case !ok:
+			events = append(events, Event{Kind: EventNew, Location: location, Alert: alert})
+		case sent.Alert.Expires != alert.Expires || sent.Alert.Description != alert.Description:
+			events = append(events, Event{Kind: EventUpdated, Location: location, Alert: alert})
+		}
+	}
+
+	// anything we sent before that is gone now has expired
+	keys := []string{}
+	for key, sent := range s.Alerts {
+		if !current[key] && sent.Location == location && sent.Webhook == id {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		events = append(events, Event{Kind: EventExpired, Location: location, Alert: s.Alerts[key].Alert})
+	}
+
+	return events
+}
+
+// Record marks the event as sent to the webhook.
+func (s *State) Record(webhook config.Webhook, event Event) {
+	id := webhookID(webhook)
+	key := stateKey(event.Location, id, event.Alert)
+	if event.Kind == EventExpired {
+		delete(s.Alerts, key)
+		return
+	}
+	s.Alerts[key] = Sent{Location: event.Location, Webhook: id, Alert: event.Alert}
+}
+
+// webhookID identifies the webhook in the state by a hash of its URL, as
+// the URLs of Slack and Matrix webhooks hold their tokens.
+func webhookID(webhook config.Webhook) string {
+	sum := sha256.Sum256([]byte(webhook.URL))
+	return hex.EncodeToString(sum[:8])
+}
+
+// stateKey dedupes alerts by their uri, or title if they have none,
+// per location and webhook.
+func stateKey(location, webhook string, alert forecast.Alert) string {
+	id := alert.URI
+	if id == "" {
+		id = alert.Title
+	}
+	return location + "|" + webhook + "|" + id
+}
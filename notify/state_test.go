@@ -0,0 +1,214 @@
+package notify
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/genuinetools/weather/config"
+	"github.com/genuinetools/weather/forecast"
+)
+
+func kinds(events []Event) []string {
+	k := []string{}
+	for _, event := range events {
+		k = append(k, event.Kind+" "+event.Location+" "+event.Alert.Title)
+	}
+	return k
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDiff(t *testing.T) {
+	const now = 1700000000
+	flood := forecast.Alert{Title: "Flood Warning", URI: "https://alerts.example.com/flood", Expires: now + 3600}
+	// the same alert retitled is deduped by its uri
+	retitled := flood
+	retitled.Title = "Flood Warning (extended)"
+	// without a uri alerts are deduped by title
+	wind := forecast.Alert{Title: "Wind Advisory", Expires: now + 3600}
+
+	hook := config.Webhook{URL: "https://hooks.example.com/a"}
+	other := config.Webhook{URL: "https://hooks.example.com/b"}
+
+	testCases := []struct {
+		name string
+		// sent were sent to hook, and toOther only to other
+		sent     []Event
+		toOther  []Event
+		location string
+		alerts   []forecast.Alert
+		expected []string
+	}{
+		{
+			name:     "new",
+			location: "home",
+			alerts:   []forecast.Alert{flood, wind},
+			expected: []string{"new home Flood Warning", "new home Wind Advisory"},
+		},
+		{
+			name:     "already sent",
+			sent:     []Event{{Kind: EventNew, Location: "home", Alert: flood}, {Kind: EventNew, Location: "home", Alert: wind}},
+			location: "home",
+			alerts:   []forecast.Alert{retitled, wind},
+			expected: []string{},
+		},
+		{
+			name:     "same alert at another location",
+			sent:     []Event{{Kind: EventNew, Location: "home", Alert: flood}},
+			location: "work",
+			alerts:   []forecast.Alert{flood},
+			expected: []string{"new work Flood Warning"},
+		},
+		{
+			name:     "sent to another webhook",
+			toOther:  []Event{{Kind: EventNew, Location: "home", Alert: flood}},
+			location: "home",
+			alerts:   []forecast.Alert{flood},
+			expected: []string{"new home Flood Warning"},
+		},
+		{
+			name:     "updated expiry",
+			sent:     []Event{{Kind: EventNew, Location: "home", Alert: flood}},
+			location: "home",
+			alerts:   []forecast.Alert{{Title: flood.Title, URI: flood.URI, Expires: now + 7200}},
+			expected: []string{"updated home Flood Warning"},
+		},
+		{
+			name:     "updated description",
+			sent:     []Event{{Kind: EventNew, Location: "home", Alert: wind}},
+			location: "home",
+			alerts:   []forecast.Alert{{Title: wind.Title, Expires: wind.Expires, Description: "Gusts to 60 mph."}},
+			expected: []string{"updated home Wind Advisory"},
+		},
+		{
+			name:     "gone",
+			sent:     []Event{{Kind: EventNew, Location: "home", Alert: wind}, {Kind: EventNew, Location: "work", Alert: flood}},
+			location: "home",
+			alerts:   []forecast.Alert{},
+			expected: []string{"expired home Wind Advisory"},
+		},
+		{
+			name:     "gone from another webhook",
+			toOther:  []Event{{Kind: EventNew, Location: "home", Alert: wind}},
+			location: "home",
+			alerts:   []forecast.Alert{},
+			expected: []string{},
+		},
+		{
+			name:     "past its expiry",
+			sent:     []Event{{Kind: EventNew, Location: "home", Alert: flood}},
+			location: "home",
+			alerts:   []forecast.Alert{{Title: flood.Title, URI: flood.URI, Expires: now}},
+			expected: []string{"expired home Flood Warning"},
+		},
+	}
+
+	for _, tc := range testCases {
+		s := &State{Alerts: map[string]Sent{}}
+		for _, event := range tc.sent {
+			s.Record(hook, event)
+		}
+		for _, event := range tc.toOther {
+			s.Record(other, event)
+		}
+		if got := kinds(s.Diff(tc.location, hook, tc.alerts, now)); !equal(got, tc.expected) {
+			t.Errorf("%s: expected events %q, got %q", tc.name, tc.expected, got)
+		}
+	}
+}
+
+// TestNotify runs alerts through their lifetime, sending the events to two
+// webhooks and keeping the state on disk in between like weather notify.
+// The second webhook is down at first and gets the alert once it is back,
+// without the first getting it again.
+func TestNotify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "weather-notify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state", "alerts.json")
+
+	up, down := newReceiver(t), newReceiver(t)
+	defer up.Close()
+	defer down.Close()
+	receivers := []*receiver{up, down}
+
+	const now = 1700000000
+	flood := forecast.Alert{Title: "Flood Warning", URI: "https://alerts.example.com/flood", Expires: now + 3600}
+	extended := flood
+	extended.Expires = now + 7200
+
+	steps := []struct {
+		alerts []forecast.Alert
+		// down is true if the second webhook is down
+		down     bool
+		expected [2][]string
+	}{
+		{alerts: []forecast.Alert{flood}, down: true, expected: [2][]string{{"new home Flood Warning"}, {}}},
+		{alerts: []forecast.Alert{flood}, expected: [2][]string{{}, {"new home Flood Warning"}}},
+		{alerts: []forecast.Alert{flood}, expected: [2][]string{{}, {}}},
+		{alerts: []forecast.Alert{extended}, expected: [2][]string{{"updated home Flood Warning"}, {"updated home Flood Warning"}}},
+		{alerts: []forecast.Alert{}, expected: [2][]string{{"expired home Flood Warning"}, {"expired home Flood Warning"}}},
+		{alerts: []forecast.Alert{}, expected: [2][]string{{}, {}}},
+	}
+
+	for i, step := range steps {
+		s, err := LoadState(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		down.status = http.StatusOK
+		if step.down {
+			down.status = http.StatusServiceUnavailable
+		}
+		for j, r := range receivers {
+			webhook := config.Webhook{URL: r.URL, Format: FormatJSON}
+			received := len(r.bodies)
+			for _, event := range s.Diff("home", webhook, step.alerts, now) {
+				if err := Send(webhook, event); err == nil {
+					s.Record(webhook, event)
+				}
+			}
+
+			got := []string{}
+			for _, body := range r.bodies[received:] {
+				if r.status != http.StatusOK {
+					continue
+				}
+				alert := body["alert"].(map[string]interface{})
+				got = append(got, body["event"].(string)+" "+body["location"].(string)+" "+alert["title"].(string))
+			}
+			if !equal(got, step.expected[j]) {
+				t.Errorf("step %d: expected webhook %d to receive %q, got %q", i, j, step.expected[j], got)
+			}
+		}
+		if err := s.Save(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the state does not hold the URLs of the webhooks
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), up.URL) {
+		t.Errorf("expected the state not to hold the webhook URLs, got %s", b)
+	}
+}
@@ -0,0 +1,154 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/genuinetools/weather/config"
+)
+
+// Webhook payload formats.
+const (
+	FormatSlack  = "slack"
+	FormatMatrix = "matrix"
+	FormatJSON   = "json"
+)
+
+// Send posts the event to the webhook in the webhook's format.
+func Send(webhook config.Webhook, event Event) error {
+	payload, err := Payload(webhook.Format, event)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling %s payload failed: %v", webhook.Format, err)
+	}
+
+	req, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range webhook.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("http request to %s failed: %v", webhook.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("http request to %s failed with status code: %v", webhook.URL, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Payload returns the body to post for the event in the format.
+func Payload(format string, event Event) (interface{}, error) {
+	switch format {
+	case FormatSlack:
+		return slackPayload(event), nil
+	case FormatMatrix:
+		return matrixPayload(event), nil
+	case FormatJSON, "":
+		return event, nil
+	}
+	return nil, fmt.Errorf("unknown webhook format %q, expected one of: slack, matrix, json", format)
+}
+
+// headline returns a one line description of the event.
+func headline(event Event) string {
+	switch event.Kind {
+	case EventNew:
+		return fmt.Sprintf("New weather alert for %s: %s", event.Location, event.Alert.Title)
+	case EventUpdated:
+		return fmt.Sprintf("Updated weather alert for %s: %s", event.Location, event.Alert.Title)
+	}
+	return fmt.Sprintf("Weather alert expired for %s: %s", event.Location, event.Alert.Title)
+}
+
+// expiry returns when the alert expires or expired, in the location's
+// time zone.
+func expiry(event Event) string {
+	if event.Alert.Expires == 0 {
+		return ""
+	}
+	loc := event.Zone
+	if loc == nil {
+		loc = time.Local
+	}
+	t := time.Unix(event.Alert.Expires, 0).In(loc).Format("January 2 at 3:04pm MST")
+	if event.Kind == EventExpired {
+		return "Expired " + t
+	}
+	return "Expires " + t
+}
+
+// slackPayload formats the event as a Slack message with blocks.
+func slackPayload(event Event) map[string]interface{} {
+	blocks := []map[string]interface{}{
+		{
+			"type": "header",
+			"text": map[string]interface{}{"type": "plain_text", "text": headline(event)},
+		},
+	}
+	if event.Kind != EventExpired && event.Alert.Description != "" {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "section",
+			"text": map[string]interface{}{"type": "mrkdwn", "text": strings.TrimSpace(event.Alert.Description)},
+		})
+	}
+
+	context := []map[string]interface{}{}
+	if e := expiry(event); e != "" {
+		context = append(context, map[string]interface{}{"type": "mrkdwn", "text": e})
+	}
+	if event.Alert.URI != "" {
+		context = append(context, map[string]interface{}{"type": "mrkdwn", "text": fmt.Sprintf("<%s|More information>", event.Alert.URI)})
+	}
+	if len(context) > 0 {
+		blocks = append(blocks, map[string]interface{}{"type": "context", "elements": context})
+	}
+
+	return map[string]interface{}{
+		"text":   headline(event),
+		"blocks": blocks,
+	}
+}
+
+// matrixPayload formats the event as the content of an m.room.message event.
+func matrixPayload(event Event) map[string]interface{} {
+	body := []string{headline(event)}
+	formatted := []string{"<strong>" + html.EscapeString(headline(event)) + "</strong>"}
+	if event.Kind != EventExpired && event.Alert.Description != "" {
+		description := strings.TrimSpace(event.Alert.Description)
+		body = append(body, description)
+		formatted = append(formatted, strings.Replace(html.EscapeString(description), "\n", "<br>", -1))
+	}
+	if e := expiry(event); e != "" {
+		body = append(body, e)
+		formatted = append(formatted, "<em>"+html.EscapeString(e)+"</em>")
+	}
+	if event.Alert.URI != "" {
+		body = append(body, event.Alert.URI)
+		formatted = append(formatted, fmt.Sprintf(`<a href="%s">More information</a>`, html.EscapeString(event.Alert.URI)))
+	}
+
+	return map[string]interface{}{
+		"msgtype":        "m.text",
+		"body":           strings.Join(body, "\n\n"),
+		"format":         "org.matrix.custom.html",
+		"formatted_body": strings.Join(formatted, "<br><br>"),
+	}
+}
@@ -0,0 +1,200 @@
+package notify
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/genuinetools/weather/config"
+	"github.com/genuinetools/weather/forecast"
+)
+
+// receiver is a webhook receiver that keeps the requests it got.
+type receiver struct {
+	*httptest.Server
+	requests []*http.Request
+	bodies   []map[string]interface{}
+	status   int
+}
+
+func newReceiver(t *testing.T) *receiver {
+	r := &receiver{status: http.StatusOK}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Errorf("reading the request body failed: %v", err)
+		}
+		var body map[string]interface{}
+		if err := json.Unmarshal(b, &body); err != nil {
+			t.Errorf("decoding the request body %s failed: %v", b, err)
+		}
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		w.WriteHeader(r.status)
+	}))
+	return r
+}
+
+var testAlert = forecast.Alert{
+	Title:       "Flood Warning",
+	Description: "Flooding along the river.\nStay away from the banks.",
+	Expires:     1700000000,
+	URI:         "https://alerts.example.com/flood",
+}
+
+func TestSendSlack(t *testing.T) {
+	r := newReceiver(t)
+	defer r.Close()
+
+	webhook := config.Webhook{URL: r.URL, Format: FormatSlack}
+	if err := Send(webhook, Event{Kind: EventNew, Location: "home", Alert: testAlert}); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.bodies) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(r.bodies))
+	}
+
+	body := r.bodies[0]
+	if expected := "New weather alert for home: Flood Warning"; body["text"] != expected {
+		t.Errorf("expected text %q, got %q", expected, body["text"])
+	}
+	blocks := body["blocks"].([]interface{})
+	if len(blocks) != 3 {
+		t.Fatalf("expected a header, section and context block, got %d blocks", len(blocks))
+	}
+	for i, kind := range []string{"header", "section", "context"} {
+		if got := blocks[i].(map[string]interface{})["type"]; got != kind {
+			t.Errorf("expected block %d to be a %s, got %v", i, kind, got)
+		}
+	}
+	section := blocks[1].(map[string]interface{})["text"].(map[string]interface{})
+	if section["text"] != strings.TrimSpace(testAlert.Description) {
+		t.Errorf("expected the description in the section, got %q", section["text"])
+	}
+	elements := blocks[2].(map[string]interface{})["elements"].([]interface{})
+	link := elements[len(elements)-1].(map[string]interface{})["text"]
+	if expected := "<https://alerts.example.com/flood|More information>"; link != expected {
+		t.Errorf("expected link %q, got %q", expected, link)
+	}
+}
+
+func TestSendMatrix(t *testing.T) {
+	r := newReceiver(t)
+	defer r.Close()
+
+	alert := testAlert
+	alert.Title = "Heat <Advisory>"
+	webhook := config.Webhook{URL: r.URL, Format: FormatMatrix}
+	if err := Send(webhook, Event{Kind: EventUpdated, Location: "home", Alert: alert}); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.bodies) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(r.bodies))
+	}
+
+	body := r.bodies[0]
+	if body["msgtype"] != "m.text" || body["format"] != "org.matrix.custom.html" {
+		t.Errorf("expected an html m.text message, got %v", body)
+	}
+	if !strings.HasPrefix(body["body"].(string), "Updated weather alert for home: Heat <Advisory>\n\n") {
+		t.Errorf("unexpected body %q", body["body"])
+	}
+	formatted := body["formatted_body"].(string)
+	for _, s := range []string{
+		"<strong>Updated weather alert for home: Heat &lt;Advisory&gt;</strong>",
+		"Flooding along the river.<br>Stay away from the banks.",
+		`<a href="https://alerts.example.com/flood">More information</a>`,
+	} {
+		if !strings.Contains(formatted, s) {
+			t.Errorf("expected formatted body to contain %q, got %q", s, formatted)
+		}
+	}
+}
+
+func TestSendJSON(t *testing.T) {
+	r := newReceiver(t)
+	defer r.Close()
+
+	webhook := config.Webhook{
+		URL:     r.URL,
+		Format:  FormatJSON,
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+	}
+	if err := Send(webhook, Event{Kind: EventExpired, Location: "home", Alert: testAlert}); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.bodies) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(r.bodies))
+	}
+
+	req := r.requests[0]
+	if req.Method != "POST" {
+		t.Errorf("expected a POST, got %s", req.Method)
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("expected content type application/json, got %q", got)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("expected the webhook headers to be sent, got authorization %q", got)
+	}
+
+	body := r.bodies[0]
+	if body["event"] != EventExpired || body["location"] != "home" {
+		t.Errorf("expected an expired event for home, got %v", body)
+	}
+	if title := body["alert"].(map[string]interface{})["title"]; title != testAlert.Title {
+		t.Errorf("expected alert title %q, got %v", testAlert.Title, title)
+	}
+}
+
+func TestSendStatus(t *testing.T) {
+	r := newReceiver(t)
+	defer r.Close()
+	r.status = http.StatusInternalServerError
+
+	err := Send(config.Webhook{URL: r.URL}, Event{Kind: EventNew, Location: "home", Alert: testAlert})
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("expected an error with the status code, got %v", err)
+	}
+}
+
+func TestExpiryZone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		event    Event
+		expected string
+	}{
+		{
+			event:    Event{Kind: EventNew, Alert: testAlert, Zone: tokyo},
+			expected: "Expires November 15 at 7:13am JST",
+		},
+		{
+			event:    Event{Kind: EventExpired, Alert: testAlert, Zone: time.UTC},
+			expected: "Expired November 14 at 10:13pm UTC",
+		},
+		{
+			event:    Event{Kind: EventNew, Alert: forecast.Alert{Title: testAlert.Title}, Zone: tokyo},
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := expiry(tc.event); got != tc.expected {
+			t.Errorf("expected %q, got %q", tc.expected, got)
+		}
+	}
+}
+
+func TestPayloadUnknownFormat(t *testing.T) {
+	if _, err := Payload("discord", Event{}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
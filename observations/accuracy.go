@@ -0,0 +1,146 @@
+package observations
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// LeadTimes are the longest lead times, of the hours forecast after the
+// previous one, the accuracy is scored for.
+var LeadTimes = []time.Duration{6 * time.Hour, 12 * time.Hour, 24 * time.Hour, 48 * time.Hour}
+
+const (
+	// rainProbability is the chance of precipitation from which an hour is
+	// forecast to be rainy.
+	rainProbability = 0.5
+	// rainIntensity is the precipitation intensity in mm/h from which an
+	// hour was rainy, Dark Sky's very light precipitation.
+	rainIntensity = 0.05
+	// matchWindow is how far in seconds the nearest observation may be from
+	// an hour for the temperature to be compared.
+	matchWindow = 30 * 60
+)
+
+// Accuracy is how accurate the forecasts from a provider were for the hours
+// forecast up to a lead time.
+type Accuracy struct {
+	Provider string `json:"provider"`
+	// From and To are the lead times in hours, the hours forecast after
+	// From up to To are scored.
+	From int `json:"fromHours"`
+	To   int `json:"toHours"`
+
+	// Hours is the number of hours forecast that were observed, and
+	// TemperatureError their mean absolute error of temperature in °C.
+	Hours            int     `json:"hours"`
+	TemperatureError float64 `json:"temperatureError"`
+
+	// Hits are rainy hours forecast to be, Misses rainy hours that were
+	// not and FalseAlarms dry hours forecast to be rainy.
+	Hits        int `json:"hits"`
+	Misses      int `json:"misses"`
+	FalseAlarms int `json:"falseAlarms"`
+	// HitRate is the share of the rainy hours forecast to be, and
+	// FalseAlarmRatio the share of the hours forecast to be rainy that
+	// were dry, zero without any.
+	HitRate         float64 `json:"hitRate"`
+	FalseAlarmRatio float64 `json:"falseAlarmRatio"`
+}
+
+// Score scores the forecasts, in order of provider, against the
+// observations, in order of time, by provider and lead time. The score of
+// the same forecasts and observations is always the same.
+func Score(forecasts []Forecast, observations []Observation) []Accuracy {
+	var scores []Accuracy
+	var sums []float64
+	for i, f := range forecasts {
+		if i == 0 || f.Provider != forecasts[i-1].Provider {
+			from := 0
+			for _, lead := range LeadTimes {
+				to := int(lead / time.Hour)
+				scores = append(scores, Accuracy{Provider: f.Provider, From: from, To: to})
+				sums = append(sums, 0)
+				from = to
+			}
+		}
+		first := len(scores) - len(LeadTimes)
+
+		for _, hour := range f.Hours {
+			lead := time.Duration(hour.Time-f.Issued) * time.Second
+			b := sort.Search(len(LeadTimes), func(i int) bool { return LeadTimes[i] >= lead })
+			if lead <= 0 || b == len(LeadTimes) {
+				continue
+			}
+
+			observed, ok := nearest(observations, hour.Time)
+			if !ok {
+				continue
+			}
+			score := &scores[first+b]
+			score.Hours++
+			sums[first+b] += math.Abs(hour.Temperature - observed.Weather.Temperature)
+
+			rainy, ok := rainyHour(observations, hour.Time)
+			if !ok {
+				continue
+			}
+			forecast := hour.PrecipProbability >= rainProbability
+			switch {
+			case rainy && forecast:
+				score.Hits++
+			case rainy:
+				score.Misses++
+			case forecast:
+				score.FalseAlarms++
+			}
+		}
+	}
+
+	// only the lead times with hours observed are scored
+	var scored []Accuracy
+	for i, score := range scores {
+		if score.Hours == 0 {
+			continue
+		}
+		score.TemperatureError = sums[i] / float64(score.Hours)
+		if n := score.Hits + score.Misses; n > 0 {
+			score.HitRate = float64(score.Hits) / float64(n)
+		}
+		if n := score.Hits + score.FalseAlarms; n > 0 {
+			score.FalseAlarmRatio = float64(score.FalseAlarms) / float64(n)
+		}
+		scored = append(scored, score)
+	}
+	return scored
+}
+
+// nearest returns the observation nearest the time, if one is within the
+// match window.
+func nearest(observations []Observation, t int64) (Observation, bool) {
+	i := sort.Search(len(observations), func(i int) bool { return observations[i].Weather.Time >= t })
+	best, ok := Observation{}, false
+	for _, j := range []int{i - 1, i} {
+		if j < 0 || j >= len(observations) {
+			continue
+		}
+		d := math.Abs(float64(observations[j].Weather.Time - t))
+		if d <= matchWindow && (!ok || d < math.Abs(float64(best.Weather.Time-t))) {
+			best, ok = observations[j], true
+		}
+	}
+	return best, ok
+}
+
+// rainyHour returns whether it rained in the hour from the time, false if
+// there are no observations of the hour.
+func rainyHour(observations []Observation, t int64) (rainy, ok bool) {
+	i := sort.Search(len(observations), func(i int) bool { return observations[i].Weather.Time >= t })
+	for ; i < len(observations) && observations[i].Weather.Time < t+60*60; i++ {
+		ok = true
+		if observations[i].Weather.PrecipIntensity >= rainIntensity {
+			return true, true
+		}
+	}
+	return false, ok
+}
@@ -0,0 +1,189 @@
+package observations
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/genuinetools/weather/forecast"
+)
+
+// start is when the first observation of the fixture was recorded.
+const start = 1709280000 // 2024-03-01 08:00 UTC
+
+// hour returns the time h hours from the start of the fixture.
+func hour(h int) int64 {
+	return start + int64(h)*60*60
+}
+
+// rainy are the hours from the start it rained.
+var rainy = map[int]bool{2: true, 3: true, 10: true, 20: true, 30: true, 40: true}
+
+// observed returns the temperature observed h hours from the start.
+func observed(h int) float64 {
+	return 10 + float64(h%5)
+}
+
+// recordFixture records the observations of the fixture and the forecasts
+// of two providers to a store:
+//
+//   - a, issued at the start for 49 hours in °C, with a temperature error
+//     of +1, -2, +3 and -4°C in each lead time bucket and rain forecast
+//     for hours 2, 5, 10, 11 and 30.
+//   - b, issued 12 hours in for 12 hours in °F, exact and with rain
+//     forecast for the rainy hours.
+//
+// The weather is observed hourly up to hour 50, except at hour 45.
+func recordFixture(t *testing.T, s *Store) {
+	for h := 0; h <= 50; h++ {
+		if h == 45 {
+			continue
+		}
+		fc := forecast.Forecast{
+			Flags:     forecast.Flags{Units: "si"},
+			Currently: forecast.Weather{Time: hour(h), Temperature: observed(h)},
+		}
+		if rainy[h] {
+			fc.Currently.PrecipIntensity = 1.2
+		}
+		if ok, err := s.Record("home", fc); err != nil || !ok {
+			t.Fatalf("recording the observation of hour %d failed: %t, %v", h, ok, err)
+		}
+	}
+
+	a := forecast.Forecast{
+		Flags:     forecast.Flags{Units: "si"},
+		Currently: forecast.Weather{Time: hour(0)},
+	}
+	for h := 1; h <= 49; h++ {
+		err := []float64{1, -2, 3, -4}[bucket(h)]
+		weather := forecast.Weather{Time: hour(h), Temperature: observed(h) + err, PrecipProbability: 0.1}
+		switch h {
+		case 2, 5, 10, 11, 30:
+			weather.PrecipProbability = 0.9
+		}
+		a.Hourly.Data = append(a.Hourly.Data, weather)
+	}
+
+	b := forecast.Forecast{
+		Flags:     forecast.Flags{Units: "us"},
+		Currently: forecast.Weather{Time: hour(12)},
+	}
+	// the hour it was issued is not scored
+	for h := 12; h <= 24; h++ {
+		weather := forecast.Weather{Time: hour(h), Temperature: observed(h)*9/5 + 32}
+		if rainy[h] {
+			weather.PrecipProbability = 1
+		}
+		b.Hourly.Data = append(b.Hourly.Data, weather)
+	}
+
+	for provider, fc := range map[string]forecast.Forecast{"a": a, "b": b} {
+		if ok, err := s.RecordForecast("home", provider, fc); err != nil || !ok {
+			t.Fatalf("recording the forecast from %s failed: %t, %v", provider, ok, err)
+		}
+	}
+}
+
+// bucket returns the lead time bucket of the hour forecast h hours ahead.
+func bucket(h int) int {
+	switch {
+	case h <= 6:
+		return 0
+	case h <= 12:
+		return 1
+	case h <= 24:
+		return 2
+	}
+	return 3
+}
+
+func TestScore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "weather-accuracy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordFixture(t, s)
+
+	observations, err := s.Load("home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	forecasts, err := s.LoadForecasts("home")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Accuracy{
+		// rainy 2 and 3, forecast 2 and 5
+		{Provider: "a", From: 0, To: 6, Hours: 6, TemperatureError: 1, Hits: 1, Misses: 1, FalseAlarms: 1, HitRate: 0.5, FalseAlarmRatio: 0.5},
+		// rainy 10, forecast 10 and 11
+		{Provider: "a", From: 6, To: 12, Hours: 6, TemperatureError: 2, Hits: 1, FalseAlarms: 1, HitRate: 1, FalseAlarmRatio: 0.5},
+		// rainy 20, none forecast
+		{Provider: "a", From: 12, To: 24, Hours: 12, TemperatureError: 3, Misses: 1},
+		// rainy 30 and 40, forecast 30, hour 45 was not observed and 49 is
+		// past the longest lead time
+		{Provider: "a", From: 24, To: 48, Hours: 23, TemperatureError: 4, Hits: 1, Misses: 1, HitRate: 0.5},
+		{Provider: "b", From: 0, To: 6, Hours: 6},
+		// rainy 20, forecast 20
+		{Provider: "b", From: 6, To: 12, Hours: 6, Hits: 1, HitRate: 1},
+	}
+
+	scores := Score(forecasts, observations)
+	if len(scores) != len(expected) {
+		t.Fatalf("expected %d scores, got %d: %+v", len(expected), len(scores), scores)
+	}
+	for i, score := range scores {
+		e := expected[i]
+		// the temperatures went through °F and back
+		if math.Abs(score.TemperatureError-e.TemperatureError) < 1e-9 {
+			score.TemperatureError = e.TemperatureError
+		}
+		if score != e {
+			t.Errorf("expected score %d to be\n%+v\ngot\n%+v", i, e, score)
+		}
+	}
+
+	// the same data always scores the same
+	again := Score(forecasts, observations)
+	for i := range scores {
+		if scores[i] != again[i] {
+			t.Errorf("expected the same score %d when scoring again, got %+v and %+v", i, scores[i], again[i])
+		}
+	}
+}
+
+func TestNearest(t *testing.T) {
+	observations := []Observation{
+		{Weather: forecast.Weather{Time: hour(0)}},
+		{Weather: forecast.Weather{Time: hour(1) - 20*60}},
+		{Weather: forecast.Weather{Time: hour(1) + 10*60}},
+		{Weather: forecast.Weather{Time: hour(3) + 40*60}},
+	}
+
+	testCases := []struct {
+		t        int64
+		expected int64
+		ok       bool
+	}{
+		{t: hour(0), expected: hour(0), ok: true},
+		{t: hour(1), expected: hour(1) + 10*60, ok: true},
+		{t: hour(2), ok: false},
+		{t: hour(3), ok: false},
+		{t: hour(4), expected: hour(3) + 40*60, ok: true},
+	}
+
+	for _, tc := range testCases {
+		o, ok := nearest(observations, tc.t)
+		if ok != tc.ok || (ok && o.Weather.Time != tc.expected) {
+			t.Errorf("nearest(%d): expected %d, %t, got %d, %t", tc.t, tc.expected, tc.ok, o.Weather.Time, ok)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package observations
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/genuinetools/weather/forecast"
+	"github.com/genuinetools/weather/units"
+)
+
+// forecastInterval is how often a forecast is recorded for each location
+// and provider, as the hourly forecast changes little more often.
+const forecastInterval = time.Hour
+
+// Hour is the forecast for an hour, with the temperature in °C and the
+// precipitation intensity in mm/h.
+type Hour struct {
+	Time              int64   `json:"time"`
+	Temperature       float64 `json:"temperature"`
+	PrecipProbability float64 `json:"precipProbability"`
+	PrecipIntensity   float64 `json:"precipIntensity"`
+}
+
+// Forecast is the hourly forecast of a location from a provider as it was
+// when it was issued.
+type Forecast struct {
+	Location string `json:"location"`
+	Provider string `json:"provider"`
+	Issued   int64  `json:"issued"`
+	Hours    []Hour `json:"hours"`
+}
+
+// forecastPath returns the file of the location's forecasts from the
+// provider.
+func (s *Store) forecastPath(provider, location string) string {
+	return filepath.Join(s.dir, "forecasts", url.QueryEscape(provider), url.PathEscape(location)+".jsonl")
+}
+
+// RecordForecast appends the hourly forecast from the provider, e.g. the
+// server it came from, to the location's forecasts, converted to the
+// canonical units. It returns false if a forecast from the provider was
+// recorded within the hour.
+func (s *Store) RecordForecast(location, provider string, fc forecast.Forecast) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.forecastPath(provider, location)
+	key := provider + "\n" + location
+	last, ok := s.issued[key]
+	if !ok {
+		line, err := lastLine(path)
+		if err != nil {
+			return false, err
+		}
+		var f Forecast
+		if line != nil && json.Unmarshal(line, &f) == nil {
+			last = f.Issued
+		}
+	}
+	if len(fc.Hourly.Data) == 0 || fc.Currently.Time < last+int64(forecastInterval/time.Second) {
+		return false, nil
+	}
+
+	fc = fc.Convert(units.Systems[units.Canonical])
+	f := Forecast{
+		Location: location,
+		Provider: provider,
+		Issued:   fc.Currently.Time,
+	}
+	for _, hourly := range fc.Hourly.Data {
+		f.Hours = append(f.Hours, Hour{
+			Time:              hourly.Time,
+			Temperature:       hourly.Temperature,
+			PrecipProbability: hourly.PrecipProbability,
+			PrecipIntensity:   hourly.PrecipIntensity,
+		})
+	}
+	b, err := json.Marshal(f)
+	if err != nil {
+		return false, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	if _, err := file.Write(append(b, '\n')); err != nil {
+		return false, err
+	}
+
+	s.issued[key] = f.Issued
+	return true, nil
+}
+
+// LoadForecasts returns the forecasts of the location from each provider
+// in order of provider and the time they were issued.
+func (s *Store) LoadForecasts(location string) ([]Forecast, error) {
+	dirs, err := ioutil.ReadDir(filepath.Join(s.dir, "forecasts"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var forecasts []Forecast
+	for _, dir := range dirs {
+		provider, err := url.QueryUnescape(dir.Name())
+		if !dir.IsDir() || err != nil {
+			continue
+		}
+		err = readLines(s.forecastPath(provider, location), func(line []byte) {
+			var f Forecast
+			if err := json.Unmarshal(line, &f); err == nil {
+				forecasts = append(forecasts, f)
+			}
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if len(forecasts) == 0 {
+		return nil, fmt.Errorf("no forecasts of %q are recorded in %s", location, s.dir)
+	}
+
+	sort.SliceStable(forecasts, func(i, j int) bool {
+		if forecasts[i].Provider != forecasts[j].Provider {
+			return forecasts[i].Provider < forecasts[j].Provider
+		}
+		return forecasts[i].Issued < forecasts[j].Issued
+	})
+	return forecasts, nil
+}
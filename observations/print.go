@@ -0,0 +1,170 @@
+package observations
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/genuinetools/weather/units"
+	"github.com/mitchellh/colorstring"
+)
+
+// dateLayout is the layout of the dates of the days.
+const dateLayout = "Mon Jan 2"
+
+// converter converts the temperatures and rainfall of days to a system of
+// units.
+type converter struct {
+	system units.System
+}
+
+func (c converter) temperature(v float64) float64 {
+	return units.TemperatureIn(v, units.Celsius).In(c.system.Temperature)
+}
+
+func (c converter) rainfall(v float64) float64 {
+	return units.PrecipRateIn(v, units.MillimetersPerHour).In(c.system.Precipitation)
+}
+
+func (c converter) degrees() string {
+	return "°" + c.system.Temperature
+}
+
+// rainUnit returns the unit of the rainfall, e.g. "mm" for "mm/h".
+func (c converter) rainUnit() string {
+	return strings.SplitN(c.system.Precipitation, "/", 2)[0]
+}
+
+// Print pretty prints the days of the location since the date in the system
+// of units, a row per day, and how the latest day compares to the rest of
+// its month.
+func Print(w io.Writer, location string, all []Day, since time.Time, system units.System) error {
+	days := Since(all, since)
+	if len(days) == 0 {
+		fmt.Fprintf(w, "No weather of %s is recorded for these days.\n", colorstring.Color("[green]"+location))
+		return nil
+	}
+	c := converter{system}
+
+	fmt.Fprintf(w, "Recorded weather in %s from %s to %s\n\n",
+		colorstring.Color("[green]"+location),
+		colorstring.Color("[magenta]"+days[0].Date.Format(dateLayout)),
+		colorstring.Color("[magenta]"+days[len(days)-1].Date.Format(dateLayout)))
+
+	fmt.Fprintf(w, "%-11s  %5s  %5s  %5s  %6s\n", c.degrees(), "Low", "High", "Avg", "Rain")
+	for _, day := range days {
+		fmt.Fprintf(w, "%-10s  %5.0f  %5.0f  %5.0f  %6s\n",
+			day.Date.Format(dateLayout),
+			c.temperature(day.Min), c.temperature(day.Max), c.temperature(day.Avg),
+			fmt.Sprintf("%.1f%s", c.rainfall(day.Rainfall), c.rainUnit()))
+	}
+	fmt.Fprintln(w)
+
+	// compare the latest day to the rest of its month
+	today := days[len(days)-1]
+	month := Since(all, time.Date(today.Date.Year(), today.Date.Month(), 1, 0, 0, 0, 0, today.Date.Location()))
+	r, _ := Compare(month)
+	fmt.Fprintf(w, "In %s so far:\n", colorstring.Color("[cyan]"+today.Date.Format("January 2006")))
+	fmt.Fprintf(w, "  Warmest day was %s at %s\n", r.Warmest.Date.Format(dateLayout),
+		colorstring.Color(fmt.Sprintf("[red]%.0f%s", c.temperature(r.Warmest.Max), c.degrees())))
+	fmt.Fprintf(w, "  Coldest day was %s at %s\n", r.Coldest.Date.Format(dateLayout),
+		colorstring.Color(fmt.Sprintf("[blue]%.0f%s", c.temperature(r.Coldest.Min), c.degrees())))
+	if r.RainyDays > 0 {
+		fmt.Fprintf(w, "  Wettest day was %s with %s\n", r.Wettest.Date.Format(dateLayout),
+			colorstring.Color(fmt.Sprintf("[cyan]%.1f%s", c.rainfall(r.Wettest.Rainfall), c.rainUnit())))
+	}
+	fmt.Fprintf(w, "  Rainfall totals %.1f%s over %d rainy %s\n", c.rainfall(r.Rainfall), c.rainUnit(), r.RainyDays, plural(r.RainyDays, "day"))
+
+	if len(month) > 1 {
+		diff := c.temperature(today.Avg) - c.temperature(r.Avg)
+		switch {
+		case math.Abs(diff) < 0.5:
+			fmt.Fprintf(w, "  Today is about the month's average of %.0f%s\n", c.temperature(r.Avg), c.degrees())
+		case diff > 0:
+			fmt.Fprintf(w, "  Today is %s warmer than the month's average of %.0f%s\n",
+				colorstring.Color(fmt.Sprintf("[red]%.0f%s", diff, c.degrees())), c.temperature(r.Avg), c.degrees())
+		default:
+			fmt.Fprintf(w, "  Today is %s colder than the month's average of %.0f%s\n",
+				colorstring.Color(fmt.Sprintf("[blue]%.0f%s", -diff, c.degrees())), c.temperature(r.Avg), c.degrees())
+		}
+	}
+
+	return nil
+}
+
+// WriteCSV writes the days as CSV in the system of units, with the units in
+// the header.
+func WriteCSV(w io.Writer, days []Day, system units.System) error {
+	c := converter{system}
+	cw := csv.NewWriter(w)
+
+	temperature := "(" + c.degrees() + ")"
+	rain := "(" + c.rainUnit() + ")"
+	if err := cw.Write([]string{"date", "min " + temperature, "max " + temperature, "avg " + temperature, "rainfall " + rain, "observations"}); err != nil {
+		return err
+	}
+	for _, day := range days {
+		if err := cw.Write([]string{
+			day.Date.Format("2006-01-02"),
+			strconv.FormatFloat(units.Round(c.temperature(day.Min)), 'f', -1, 64),
+			strconv.FormatFloat(units.Round(c.temperature(day.Max)), 'f', -1, 64),
+			strconv.FormatFloat(units.Round(c.temperature(day.Avg)), 'f', -1, 64),
+			strconv.FormatFloat(units.Round(c.rainfall(day.Rainfall)), 'f', -1, 64),
+			strconv.Itoa(day.Observations),
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// PrintAccuracy pretty prints the accuracy of the forecasts of the location
+// in the system of units, a table of the lead times for each provider.
+func PrintAccuracy(w io.Writer, location string, scores []Accuracy, system units.System) error {
+	if len(scores) == 0 {
+		fmt.Fprintf(w, "None of the recorded forecasts of %s have been observed yet.\n", colorstring.Color("[green]"+location))
+		return nil
+	}
+	c := converter{system}
+	// the error is a difference of temperatures, so only scaled
+	scale := c.temperature(1) - c.temperature(0)
+
+	fmt.Fprintf(w, "Accuracy of the forecasts for %s\n", colorstring.Color("[green]"+location))
+	for i, score := range scores {
+		if i == 0 || score.Provider != scores[i-1].Provider {
+			fmt.Fprintf(w, "\n%s\n", colorstring.Color("[cyan]"+score.Provider))
+			fmt.Fprintf(w, "  %-9s  %6s  %9s  %5s  %6s  %12s  %8s  %8s\n",
+				"Lead time", "Hours", "Temp MAE", "Hits", "Misses", "False alarms", "Hit rate", "FA ratio")
+		}
+		// the degree sign is two bytes but one column wide
+		fmt.Fprintf(w, "  %-9s  %6d  %10s  %5d  %6d  %12d  %8s  %8s\n",
+			fmt.Sprintf("%d-%dh", score.From, score.To),
+			score.Hours,
+			fmt.Sprintf("%.1f%s", score.TemperatureError*scale, c.degrees()),
+			score.Hits, score.Misses, score.FalseAlarms,
+			percent(score.HitRate, score.Hits+score.Misses),
+			percent(score.FalseAlarmRatio, score.Hits+score.FalseAlarms))
+	}
+	return nil
+}
+
+// percent formats the rate as a percentage, or "-" if it is of nothing.
+func percent(rate float64, of int) string {
+	if of == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", rate*100)
+}
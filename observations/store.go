@@ -0,0 +1,218 @@
+// Package observations records the current weather of locations over time
+// in a local store and reports the trends in it, and how accurate the
+// forecasts recorded along with it turned out to be.
+//
+// The store is a file per location of JSON lines, one per observation, in
+// the canonical units of the units package, so it needs nothing else
+// installed and keeps working across changes of units.
+package observations
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/genuinetools/weather/forecast"
+	"github.com/genuinetools/weather/units"
+)
+
+// Observation is the current weather of a location at a time.
+type Observation struct {
+	Location string `json:"location"`
+	// Timezone is the time zone of the location, for the days the
+	// observations fall on.
+	Timezone string           `json:"timezone"`
+	Weather  forecast.Weather `json:"weather"`
+}
+
+// Store holds the observations of each location in a directory.
+type Store struct {
+	dir string
+
+	mu sync.Mutex
+	// last is the time of the last observation recorded for each
+	// location, so an unchanged current weather is not recorded twice.
+	last map[string]int64
+	// issued is the time the last forecast was recorded for each provider
+	// and location.
+	issued map[string]int64
+}
+
+// Open opens the store in the directory, creating it if needed.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir, last: map[string]int64{}, issued: map[string]int64{}}, nil
+}
+
+// path returns the file of the location's observations.
+func (s *Store) path(location string) string {
+	return filepath.Join(s.dir, url.PathEscape(location)+".jsonl")
+}
+
+// Record appends the current weather of the forecast to the location's
+// observations, converted to the canonical units. It returns false if the
+// current weather was already recorded.
+func (s *Store) Record(location string, fc forecast.Forecast) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.path(location)
+	last, ok := s.last[location]
+	if !ok {
+		var err error
+		if last, err = lastTime(path); err != nil {
+			return false, err
+		}
+	}
+	if fc.Currently.Time <= last {
+		return false, nil
+	}
+
+	fc = fc.Convert(units.Systems[units.Canonical])
+	b, err := json.Marshal(Observation{
+		Location: location,
+		Timezone: fc.Timezone,
+		Weather:  fc.Currently,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		return false, err
+	}
+
+	s.last[location] = fc.Currently.Time
+	return true, nil
+}
+
+// lastTime returns the time of the last observation in the file, or zero
+// if there are none.
+func lastTime(path string) (int64, error) {
+	line, err := lastLine(path)
+	if err != nil || line == nil {
+		return 0, err
+	}
+	var o Observation
+	if err := json.Unmarshal(line, &o); err != nil {
+		return 0, nil
+	}
+	return o.Weather.Time, nil
+}
+
+// lastLine returns the last line of the file, or nil if there is none.
+func lastLine(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// only the end of the file is read, it grows for good
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := info.Size() - 64*1024
+	if offset < 0 {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return nil, nil
+	}
+	lines := bytes.Split(b, []byte("\n"))
+	return lines[len(lines)-1], nil
+}
+
+// Load returns the observations of the location in order of time.
+func (s *Store) Load(location string) ([]Observation, error) {
+	var observations []Observation
+	err := readLines(s.path(location), func(line []byte) {
+		var o Observation
+		if err := json.Unmarshal(line, &o); err == nil {
+			observations = append(observations, o)
+		}
+	})
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no observations of %q are recorded in %s", location, s.dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(observations, func(i, j int) bool {
+		return observations[i].Weather.Time < observations[j].Weather.Time
+	})
+	return observations, nil
+}
+
+// readLines calls fn with each line of the file that is not blank. A line
+// cut short by a crash while writing fails to decode and is to be skipped.
+func readLines(path string, fn func(line []byte)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		fn(scanner.Bytes())
+	}
+	return scanner.Err()
+}
+
+// Locations returns the names of the locations with observations in sorted
+// order.
+func (s *Store) Locations() ([]string, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), ".jsonl") {
+			continue
+		}
+		name, err := url.PathUnescape(strings.TrimSuffix(f.Name(), ".jsonl"))
+		if err != nil {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,135 @@
+package observations
+
+import (
+	"math"
+	"time"
+)
+
+// maxRainGap is the longest time between observations the rainfall is
+// counted over, so a gap in recording does not count as a downpour.
+const maxRainGap = time.Hour
+
+// Day sums up the observations of a day. The temperatures are in °C and
+// the rainfall in mm.
+type Day struct {
+	Date         time.Time `json:"date"`
+	Min          float64   `json:"min"`
+	Max          float64   `json:"max"`
+	Avg          float64   `json:"avg"`
+	Rainfall     float64   `json:"rainfall"`
+	Observations int       `json:"observations"`
+}
+
+// Location returns the time zone of the observations, that of the latest
+// one, or local time if it is unknown.
+func Location(observations []Observation) *time.Location {
+	if len(observations) > 0 {
+		if loc, err := time.LoadLocation(observations[len(observations)-1].Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+// Days sums up the observations, in order of time, by the day in loc they
+// fall on. The rainfall is the precipitation intensity of each
+// observation until the next, split between the days at midnight.
+func Days(observations []Observation, loc *time.Location) []Day {
+	var days []Day
+	var sum float64
+	// rainfall is the rain by the start of the day it fell on
+	rainfall := map[int64]float64{}
+	for i, o := range observations {
+		date := dayOf(time.Unix(o.Weather.Time, 0), loc)
+
+		if len(days) == 0 || !days[len(days)-1].Date.Equal(date) {
+			days = append(days, Day{Date: date, Min: math.Inf(1), Max: math.Inf(-1)})
+			sum = 0
+		}
+		day := &days[len(days)-1]
+
+		temperature := o.Weather.Temperature
+		day.Min = math.Min(day.Min, temperature)
+		day.Max = math.Max(day.Max, temperature)
+		day.Observations++
+		sum += temperature
+		day.Avg = sum / float64(day.Observations)
+
+		if i+1 < len(observations) {
+			start := time.Unix(o.Weather.Time, 0)
+			end := time.Unix(observations[i+1].Weather.Time, 0)
+			if end.Sub(start) > maxRainGap {
+				end = start.Add(maxRainGap)
+			}
+			for start.Before(end) {
+				date := dayOf(start, loc)
+				until := date.AddDate(0, 0, 1)
+				if end.Before(until) {
+					until = end
+				}
+				rainfall[date.Unix()] += o.Weather.PrecipIntensity * until.Sub(start).Hours()
+				start = until
+			}
+		}
+	}
+
+	// the rain of a day with no observations of its own is left out
+	for i := range days {
+		days[i].Rainfall = rainfall[days[i].Date.Unix()]
+	}
+	return days
+}
+
+// dayOf returns the start of the day in loc the time falls on.
+func dayOf(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// Since returns the days from the date on.
+func Since(days []Day, date time.Time) []Day {
+	for i, day := range days {
+		if !day.Date.Before(date) {
+			return days[i:]
+		}
+	}
+	return nil
+}
+
+// Report compares the days of a period, e.g. a month.
+type Report struct {
+	Warmest, Coldest, Wettest Day
+	// Rainfall is the total rainfall and RainyDays the days with at
+	// least 1mm.
+	Rainfall  float64
+	RainyDays int
+	// Avg is the average of the days' average temperatures.
+	Avg float64
+}
+
+// Compare returns the report of the days, false if there are none.
+func Compare(days []Day) (Report, bool) {
+	if len(days) == 0 {
+		return Report{}, false
+	}
+
+	r := Report{Warmest: days[0], Coldest: days[0], Wettest: days[0]}
+	for _, day := range days {
+		if day.Max > r.Warmest.Max {
+			r.Warmest = day
+		}
+		if day.Min < r.Coldest.Min {
+			r.Coldest = day
+		}
+		if day.Rainfall > r.Wettest.Rainfall {
+			r.Wettest = day
+		}
+		r.Rainfall += day.Rainfall
+		if day.Rainfall >= 1 {
+			r.RainyDays++
+		}
+		r.Avg += day.Avg
+	}
+	r.Avg /= float64(len(days))
+	return r, true
+}
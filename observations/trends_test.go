@@ -0,0 +1,150 @@
+package observations
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/genuinetools/weather/forecast"
+	"github.com/genuinetools/weather/units"
+)
+
+// newYork is the time zone of the days of the tests.
+var newYork = func() *time.Location {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}()
+
+// observation returns an observation at the time on a day of March 2024 in
+// New York.
+func observation(day, hour, min int, temperature, intensity float64) Observation {
+	return Observation{
+		Location: "home",
+		Timezone: "America/New_York",
+		Weather: forecast.Weather{
+			Time:            time.Date(2024, time.March, day, hour, min, 0, 0, newYork).Unix(),
+			Temperature:     temperature,
+			PrecipIntensity: intensity,
+		},
+	}
+}
+
+func march(day int) time.Time {
+	return time.Date(2024, time.March, day, 0, 0, 0, 0, newYork)
+}
+
+func TestDays(t *testing.T) {
+	observations := []Observation{
+		observation(1, 22, 0, 5, 0),
+		// 2mm/h until the gap is cut short at 0:30, half of it after
+		// midnight
+		observation(1, 23, 30, 3, 2),
+		observation(2, 0, 45, 2, 0),
+		observation(2, 1, 15, 4, 4),
+		// an hour of the gap to the next day with observations
+		observation(2, 1, 45, 6, 1),
+		observation(4, 12, 0, 10, 0),
+		// the half hour on the 5th, with no observations, is left out
+		observation(4, 23, 30, 8, 6),
+		observation(6, 8, 0, 0, 0),
+	}
+
+	if loc := Location(observations); loc.String() != "America/New_York" {
+		t.Errorf("expected the days in New York, got %s", loc)
+	}
+
+	expected := []Day{
+		{Date: march(1), Min: 3, Max: 5, Avg: 4, Rainfall: 1, Observations: 2},
+		{Date: march(2), Min: 2, Max: 6, Avg: 4, Rainfall: 4, Observations: 3},
+		{Date: march(4), Min: 8, Max: 10, Avg: 9, Rainfall: 3, Observations: 2},
+		{Date: march(6), Min: 0, Max: 0, Avg: 0, Rainfall: 0, Observations: 1},
+	}
+	if days := Days(observations, newYork); !reflect.DeepEqual(days, expected) {
+		t.Errorf("expected\n%+v\ngot\n%+v", expected, days)
+	}
+
+	// the same observations fall on other days in Tokyo
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	days := Days(observations[:5], tokyo)
+	if len(days) != 1 || days[0].Observations != 5 || days[0].Rainfall != 4 {
+		t.Errorf("expected one day in Tokyo with 4mm of rain, got %+v", days)
+	}
+
+	if since := Since(Days(observations, newYork), march(3)); len(since) != 2 || !since[0].Date.Equal(march(4)) {
+		t.Errorf("expected the days from the 4th, got %+v", since)
+	}
+	if since := Since(Days(observations, newYork), march(7)); since != nil {
+		t.Errorf("expected no days from the 7th, got %+v", since)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	if _, ok := Compare(nil); ok {
+		t.Error("expected no report without days")
+	}
+
+	days := []Day{
+		{Date: march(1), Min: 3, Max: 12, Avg: 8, Rainfall: 0.5},
+		{Date: march(2), Min: -2, Max: 6, Avg: 2, Rainfall: 12},
+		{Date: march(3), Min: 4, Max: 15, Avg: 11, Rainfall: 1},
+		// ties keep the first day
+		{Date: march(4), Min: -2, Max: 15, Avg: 7, Rainfall: 0},
+	}
+	r, ok := Compare(days)
+	if !ok {
+		t.Fatal("expected a report")
+	}
+	expected := Report{
+		Warmest:   days[2],
+		Coldest:   days[1],
+		Wettest:   days[1],
+		Rainfall:  13.5,
+		RainyDays: 2,
+		Avg:       7,
+	}
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %+v, got %+v", expected, r)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	days := []Day{
+		{Date: march(1), Min: 0, Max: 12.5, Avg: 6.25, Rainfall: 25.4, Observations: 144},
+		{Date: march(2), Min: -3.3, Max: 1, Avg: -1.111, Rainfall: 0, Observations: 1},
+	}
+
+	testCases := []struct {
+		system   units.System
+		expected string
+	}{
+		{
+			system: units.Systems["si"],
+			expected: "date,min (°C),max (°C),avg (°C),rainfall (mm),observations\n" +
+				"2024-03-01,0,12.5,6.25,25.4,144\n" +
+				"2024-03-02,-3.3,1,-1.11,0,1\n",
+		},
+		{
+			system: units.Systems["us"],
+			expected: "date,min (°F),max (°F),avg (°F),rainfall (in),observations\n" +
+				"2024-03-01,32,54.5,43.25,1,144\n" +
+				"2024-03-02,26.06,33.8,30,0,1\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		if err := WriteCSV(&buf, days, tc.system); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tc.expected {
+			t.Errorf("%s: expected\n%s\ngot\n%s", tc.system.Name(), tc.expected, buf.String())
+		}
+	}
+}
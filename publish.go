@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/genuinetools/weather/config"
+	"github.com/genuinetools/weather/forecast"
+	"github.com/genuinetools/weather/geocode"
+	"github.com/genuinetools/weather/mqtt"
+	"github.com/sirupsen/logrus"
+)
+
+const publishHelp = `Publish the weather for the saved locations to MQTT.
+
+For each location saved in the config file the current weather is published
+as retained messages to <prefix>/<location>/<field> and as a JSON object to
+<prefix>/<location>/state. Home Assistant discovery messages are published to
+<discovery-prefix>/sensor/weather_<location>/<field>/config so the sensors
+show up automatically. In topics the location name is lowercased and anything
+but letters and digits replaced by underscores, e.g. "New York" becomes
+new_york.`
+
+func (cmd *publishCommand) Name() string      { return "publish" }
+func (cmd *publishCommand) Args() string      { return "[OPTIONS]" }
+func (cmd *publishCommand) ShortHelp() string { return "Publish the weather to MQTT." }
+func (cmd *publishCommand) LongHelp() string  { return publishHelp }
+func (cmd *publishCommand) Hidden() bool      { return false }
+
+func (cmd *publishCommand) Register(fs *flag.FlagSet) {
+	fs.StringVar(&cmd.broker, "broker", "tcp://localhost:1883", "MQTT broker uri (tcp:// or ssl://)")
+	fs.StringVar(&cmd.username, "username", "", "MQTT username")
+	fs.StringVar(&cmd.password, "password", os.Getenv("MQTT_PASSWORD"), "MQTT password (or set MQTT_PASSWORD)")
+	fs.StringVar(&cmd.prefix, "topic-prefix", "weather", "prefix for the topics the weather is published to")
+	fs.StringVar(&cmd.discoveryPrefix, "discovery-prefix", "homeassistant", "Home Assistant discovery prefix (empty disables discovery)")
+	fs.DurationVar(&cmd.interval, "interval", 10*time.Minute, "how often to publish the weather")
+	fs.BoolVar(&cmd.once, "once", false, "publish the weather once and exit")
+}
+
+type publishCommand struct {
+	broker          string
+	username        string
+	password        string
+	prefix          string
+	discoveryPrefix string
+	interval        time.Duration
+	once            bool
+
+	geocodes map[string]geocode.Geocode
+}
+
+// mqttState is the JSON object published to <prefix>/<location>/state.
+type mqttState struct {
+	Temperature         float64  `json:"temperature"`
+	ApparentTemperature float64  `json:"apparent_temperature"`
+	Humidity            float64  `json:"humidity"`
+	WindSpeed           float64  `json:"wind_speed"`
+	WindBearing         float64  `json:"wind_bearing"`
+	PrecipProbability   float64  `json:"precip_probability"`
+	Pressure            float64  `json:"pressure"`
+	Summary             string   `json:"summary"`
+	Icon                string   `json:"icon"`
+	Alerts              []string `json:"alerts"`
+	Time                int64    `json:"time"`
+}
+
+// mqttSensor describes a Home Assistant sensor for a field of mqttState.
+type mqttSensor struct {
+	field       string
+	name        string
+	deviceClass string
+	unit        func(forecast.UnitMeasures) string
+}
+
+var mqttSensors = []mqttSensor{
+	{field: "temperature", name: "Temperature", deviceClass: "temperature", unit: func(u forecast.UnitMeasures) string { return u.Degrees }},
+	{field: "apparent_temperature", name: "Feels Like", deviceClass: "temperature", unit: func(u forecast.UnitMeasures) string { return u.Degrees }},
+	{field: "humidity", name: "Humidity", deviceClass: "humidity", unit: func(u forecast.UnitMeasures) string { return "%" }},
+	{field: "wind_speed", name: "Wind Speed", deviceClass: "wind_speed", unit: func(u forecast.UnitMeasures) string { return u.Speed }},
+	{field: "precip_probability", name: "Precipitation Probability", unit: func(u forecast.UnitMeasures) string { return "%" }},
+	{field: "pressure", name: "Pressure", deviceClass: "pressure", unit: func(u forecast.UnitMeasures) string { return u.Pressure }},
+	{field: "summary", name: "Summary"},
+	{field: "alerts", name: "Alerts"},
+}
+
+func (cmd *publishCommand) Run(ctx context.Context, args []string) error {
+	if !cmd.once && cmd.interval < minWatchInterval {
+		return fmt.Errorf("interval must be at least %s", minWatchInterval)
+	}
+	// MQTT only sends a password along with a username
+	if cmd.password != "" && cmd.username == "" {
+		return fmt.Errorf("a password needs a username, pass -username or unset MQTT_PASSWORD")
+	}
+
+	conf, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if len(conf.Locations) < 1 {
+		return fmt.Errorf("no locations saved in the config file %s", configPath)
+	}
+
+	ctx, cancel := withSignals(ctx)
+	defer cancel()
+
+	cmd.geocodes = map[string]geocode.Geocode{}
+	ticker := time.NewTicker(cmd.interval)
+	defer ticker.Stop()
+	for {
+		if err := cmd.publish(conf); err != nil {
+			if cmd.once {
+				return err
+			}
+			logrus.Warnf("publishing the weather failed: %v", err)
+		}
+
+		if cmd.once {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// publish connects to the broker and publishes the weather for all the
+// saved locations.
+func (cmd *publishCommand) publish(conf config.Config) error {
+	client, err := mqtt.Connect(mqtt.Options{
+		Broker:   cmd.broker,
+		ClientID: fmt.Sprintf("weather-%d", os.Getpid()),
+		Username: cmd.username,
+		Password: cmd.password,
+	})
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	for _, name := range conf.LocationNames() {
+		g, err := locateSaved(conf, cmd.geocodes, name)
+		if err != nil {
+			logrus.Warnf("locating %s failed: %v", name, err)
+			continue
+		}
+
+		fc, err := getForecast(g, "minutely", "hourly", "daily")
+		if err != nil {
+			logrus.Warnf("getting the forecast for %s failed: %v", name, err)
+			continue
+		}
+
+		if err := cmd.publishLocation(client, name, fc); err != nil {
+			return err
+		}
+		logrus.Infof("published the weather for %s", name)
+	}
+
+	return nil
+}
+
+// publishLocation publishes the discovery, state and field messages for
+// a location.
+func (cmd *publishCommand) publishLocation(client *mqtt.Client, name string, fc forecast.Forecast) error {
+	base := cmd.prefix + "/" + mqttSlug(name)
+	state := mqttState{
+		Temperature:         fc.Currently.Temperature,
+		ApparentTemperature: fc.Currently.ApparentTemperature,
+		Humidity:            math.Round(fc.Currently.Humidity * 100),
+		WindSpeed:           fc.Currently.WindSpeed,
+		WindBearing:         fc.Currently.WindBearing,
+		PrecipProbability:   math.Round(fc.Currently.PrecipProbability * 100),
+		Pressure:            fc.Currently.Pressure,
+		Summary:             fc.Currently.Summary,
+		Icon:                fc.Currently.Icon,
+		Alerts:              []string{},
+		Time:                fc.Currently.Time,
+	}
+	for _, alert := range fc.Alerts {
+		state.Alerts = append(state.Alerts, alert.Title)
+	}
+
+	if cmd.discoveryPrefix != "" {
+		unitsFormat := fc.Units()
+		for _, sensor := range mqttSensors {
+			b, err := json.Marshal(cmd.discoveryConfig(name, sensor, unitsFormat))
+			if err != nil {
+				return err
+			}
+			topic := fmt.Sprintf("%s/sensor/weather_%s/%s/config", cmd.discoveryPrefix, mqttSlug(name), sensor.field)
+			if err := client.Publish(topic, b, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := client.Publish(base+"/state", b, true); err != nil {
+		return err
+	}
+
+	alerts, err := json.Marshal(state.Alerts)
+	if err != nil {
+		return err
+	}
+	fields := map[string]string{
+		"temperature":          formatFloat(state.Temperature),
+		"apparent_temperature": formatFloat(state.ApparentTemperature),
+		"humidity":             formatFloat(state.Humidity),
+		"wind_speed":           formatFloat(state.WindSpeed),
+		"wind_bearing":         formatFloat(state.WindBearing),
+		"precip_probability":   formatFloat(state.PrecipProbability),
+		"pressure":             formatFloat(state.Pressure),
+		"summary":              state.Summary,
+		"icon":                 state.Icon,
+		"alerts":               string(alerts),
+	}
+	for field, value := range fields {
+		if err := client.Publish(base+"/"+field, []byte(value), true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// discoveryConfig returns the Home Assistant MQTT discovery config for a
+// sensor of a location.
+func (cmd *publishCommand) discoveryConfig(name string, sensor mqttSensor, unitsFormat forecast.UnitMeasures) map[string]interface{} {
+	slug := mqttSlug(name)
+	c := map[string]interface{}{
+		"name":           sensor.name,
+		"unique_id":      fmt.Sprintf("weather_%s_%s", slug, sensor.field),
+		"state_topic":    cmd.prefix + "/" + slug + "/state",
+		"value_template": fmt.Sprintf("{{ value_json.%s }}", sensor.field),
+		"device": map[string]interface{}{
+			"identifiers":  []string{"weather_" + slug},
+			"name":         "Weather " + name,
+			"manufacturer": "genuinetools",
+			"model":        "weather",
+		},
+	}
+	if sensor.deviceClass != "" {
+		c["device_class"] = sensor.deviceClass
+		c["state_class"] = "measurement"
+	}
+	if sensor.unit != nil {
+		c["unit_of_measurement"] = sensor.unit(unitsFormat)
+	}
+	if sensor.field == "alerts" {
+		c["value_template"] = "{{ value_json.alerts | count }}"
+		c["json_attributes_topic"] = cmd.prefix + "/" + slug + "/state"
+		c["json_attributes_template"] = "{{ {'alerts': value_json.alerts} | tojson }}"
+		c["icon"] = "mdi:alert"
+	}
+	if sensor.field == "precip_probability" {
+		c["icon"] = "mdi:weather-rainy"
+	}
+	return c
+}
+
+// mqttSlug returns the location name as a topic level and Home Assistant
+// object id, lowercased with every run of characters other than a-z and
+// 0-9 replaced by an underscore, so names with spaces or the topic
+// separator and wildcards "/", "+" and "#" are safe to use.
+func mqttSlug(name string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if underscore && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+			underscore = false
+			continue
+		}
+		underscore = true
+	}
+	if b.Len() == 0 {
+		return "location"
+	}
+	return b.String()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/genuinetools/weather/forecast"
+)
+
+func TestMQTTSlug(t *testing.T) {
+	testCases := map[string]string{
+		"home":            "home",
+		"New York":        "new_york",
+		"home/garden":     "home_garden",
+		"+#":              "location",
+		"Sydney, NSW #2 ": "sydney_nsw_2",
+		"São Paulo":       "s_o_paulo",
+	}
+
+	for name, expected := range testCases {
+		if got := mqttSlug(name); got != expected {
+			t.Errorf("mqttSlug(%q): expected %q, got %q", name, expected, got)
+		}
+	}
+}
+
+func TestDiscoveryConfig(t *testing.T) {
+	cmd := &publishCommand{prefix: "weather"}
+	c := cmd.discoveryConfig("New York/+", mqttSensors[0], forecast.UnitMeasures{Degrees: "°F"})
+
+	if c["unique_id"] != "weather_new_york_temperature" {
+		t.Errorf("unexpected unique_id %q", c["unique_id"])
+	}
+	if c["state_topic"] != "weather/new_york/state" {
+		t.Errorf("unexpected state_topic %q", c["state_topic"])
+	}
+	device := c["device"].(map[string]interface{})
+	if device["name"] != "Weather New York/+" {
+		t.Errorf("expected the device to keep the location name, got %q", device["name"])
+	}
+	if ids := device["identifiers"].([]string); len(ids) != 1 || ids[0] != "weather_new_york" {
+		t.Errorf("unexpected device identifiers %q", ids)
+	}
+}
+
+func TestPublishPasswordWithoutUsername(t *testing.T) {
+	testCases := []struct {
+		env  string
+		args []string
+		err  bool
+	}{
+		{env: "secret", args: []string{"-once"}, err: true},
+		{args: []string{"-once", "-password", "secret"}, err: true},
+		{env: "secret", args: []string{"-once", "-username", "weather"}},
+		{args: []string{"-once"}},
+	}
+
+	// no locations are saved, so the runs that get past the credentials
+	// stop there instead of connecting
+	path := configPath
+	t.Cleanup(func() { configPath = path })
+	configPath = filepath.Join(t.TempDir(), "config.json")
+
+	for _, tc := range testCases {
+		t.Setenv("MQTT_PASSWORD", tc.env)
+
+		cmd := &publishCommand{}
+		fs := flag.NewFlagSet("publish", flag.ContinueOnError)
+		cmd.Register(fs)
+		if err := fs.Parse(tc.args); err != nil {
+			t.Fatal(err)
+		}
+
+		err := cmd.Run(context.Background(), nil)
+		if got := err != nil && strings.Contains(err.Error(), "a password needs a username"); got != tc.err {
+			t.Errorf("MQTT_PASSWORD=%q %v: expected the password error %t, got %v", tc.env, tc.args, tc.err, err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/genuinetools/weather/config"
+	"github.com/genuinetools/weather/geocode"
+	"github.com/genuinetools/weather/observations"
+	"github.com/sirupsen/logrus"
+)
+
+const recordHelp = `Record the current weather and forecast of the saved locations.
+
+Locations are read from the config file. The observations are kept in a file
+per location in the directory, for "weather trends" to report on, and the
+hourly forecast once an hour, for "weather accuracy" to score. The server the
+forecasts come from is recorded as their provider.`
+
+func (cmd *recordCommand) Name() string      { return "record" }
+func (cmd *recordCommand) Args() string      { return "[OPTIONS]" }
+func (cmd *recordCommand) ShortHelp() string { return "Record the current weather." }
+func (cmd *recordCommand) LongHelp() string  { return recordHelp }
+func (cmd *recordCommand) Hidden() bool      { return false }
+
+func (cmd *recordCommand) Register(fs *flag.FlagSet) {
+	fs.DurationVar(&cmd.interval, "interval", 10*time.Minute, "how often to record the weather")
+	fs.BoolVar(&cmd.once, "once", false, "record the weather once and exit")
+	fs.StringVar(&cmd.dir, "dir", filepath.Join(config.StateDir(), "observations"), "directory to keep the observations in")
+}
+
+type recordCommand struct {
+	interval time.Duration
+	once     bool
+	dir      string
+
+	geocodes map[string]geocode.Geocode
+}
+
+func (cmd *recordCommand) Run(ctx context.Context, args []string) error {
+	if !cmd.once && cmd.interval < minWatchInterval {
+		return fmt.Errorf("interval must be at least %s", minWatchInterval)
+	}
+
+	conf, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if len(conf.Locations) < 1 {
+		return fmt.Errorf("no locations saved in the config file %s", configPath)
+	}
+
+	store, err := observations.Open(cmd.dir)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withSignals(ctx)
+	defer cancel()
+
+	cmd.geocodes = map[string]geocode.Geocode{}
+	ticker := time.NewTicker(cmd.interval)
+	defer ticker.Stop()
+	for {
+		for _, name := range conf.LocationNames() {
+			if err := cmd.record(conf, store, name); err != nil {
+				logrus.Warnf("recording the weather of %s failed: %v", name, err)
+			}
+		}
+
+		if cmd.once {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// record records the current weather and forecast of a saved location.
+func (cmd *recordCommand) record(conf config.Config, store *observations.Store, name string) error {
+	g, err := locateSaved(conf, cmd.geocodes, name)
+	if err != nil {
+		return err
+	}
+
+	fc, err := getForecast(g, "minutely", "daily", "alerts")
+	if err != nil {
+		return err
+	}
+
+	if _, err := store.RecordForecast(name, server, fc); err != nil {
+		return err
+	}
+
+	recorded, err := store.Record(name, fc)
+	if err != nil {
+		return err
+	}
+	if recorded {
+		logrus.Infof("recorded the weather of %s at %s", name, time.Unix(fc.Currently.Time, 0).Format(time.RFC3339))
+	}
+	return nil
+}
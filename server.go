@@ -8,11 +8,12 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
-const (
+var (
 	darkskyAPIURI = "https://api.darksky.net/forecast"
 	geocodeAPIURI = "https://maps.googleapis.com/maps/api/geocode/json"
 )
@@ -32,6 +33,8 @@ func (cmd *serverCommand) Register(fs *flag.FlagSet) {
 	fs.StringVar(&cmd.cert, "cert", "", "path to ssl cert")
 	fs.StringVar(&cmd.key, "key", "", "path to ssl key")
 	fs.StringVar(&cmd.port, "port", "1234", "port for server to run on")
+
+	fs.DurationVar(&cmd.cacheTTL, "cache-ttl", 5*time.Minute, "how long to cache forecast responses (0 disables the cache)")
 }
 
 type serverCommand struct {
@@ -41,11 +44,14 @@ type serverCommand struct {
 	cert string
 	key  string
 	port string
+
+	cacheTTL time.Duration
+	cache    *responseCache
 }
 
 func (cmd *serverCommand) Run(ctx context.Context, args []string) error {
 	// On ^C, or SIGTERM handle exit.
-	signals := make(chan os.Signal)
+	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, os.Interrupt)
 	signal.Notify(signals, syscall.SIGTERM)
 	var cancel context.CancelFunc
@@ -66,12 +72,15 @@ func (cmd *serverCommand) Run(ctx context.Context, args []string) error {
 		logrus.Fatalf("Please pass a Google Maps Geocode API Key")
 	}
 
+	cmd.cache = newResponseCache(cmd.cacheTTL)
+
 	// Create mux server.
 	mux := http.NewServeMux()
 
-	mux.HandleFunc("/forecast", cmd.forecastHandler) // forecast handler
-	mux.HandleFunc("/geocode", cmd.geocodeHandler)   // geocode handler
-	mux.HandleFunc("/", failHandler)                 // everything else fail handler
+	mux.HandleFunc("/forecast", cmd.forecastHandler)            // forecast handler
+	mux.HandleFunc("/forecast/batch", cmd.forecastBatchHandler) // forecast batch handler
+	mux.HandleFunc("/geocode", cmd.geocodeHandler)              // geocode handler
+	mux.HandleFunc("/", failHandler)                            // everything else fail handler
 
 	// Set up the server.
 	server := &http.Server{
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/genuinetools/weather/forecast"
+)
+
+const statusbarHelp = `Stream the weather to a status bar.
+
+With the default format this speaks the i3bar protocol so it can be used as
+the status_command of i3bar or swaybar. With -format waybar it writes one line
+of JSON per refresh for a Waybar custom module.`
+
+func (cmd *statusbarCommand) Name() string      { return "statusbar" }
+func (cmd *statusbarCommand) Args() string      { return "[OPTIONS]" }
+func (cmd *statusbarCommand) ShortHelp() string { return "Stream the weather to a status bar." }
+func (cmd *statusbarCommand) LongHelp() string  { return statusbarHelp }
+func (cmd *statusbarCommand) Hidden() bool      { return false }
+
+func (cmd *statusbarCommand) Register(fs *flag.FlagSet) {
+	fs.DurationVar(&cmd.interval, "interval", 10*time.Minute, "how often to refresh the weather")
+}
+
+type statusbarCommand struct {
+	interval time.Duration
+}
+
+func (cmd *statusbarCommand) Run(ctx context.Context, args []string) error {
+	if cmd.interval < minWatchInterval {
+		return fmt.Errorf("interval must be at least %s", minWatchInterval)
+	}
+
+	if format == "text" && tmplText == "" && tmplFile == "" {
+		format = "i3bar"
+	}
+	i3bar := format == "i3bar" && tmplText == "" && tmplFile == ""
+
+	ctx, cancel := withSignals(ctx)
+	defer cancel()
+
+	g, err := getLocation()
+	if err != nil {
+		return err
+	}
+
+	if i3bar {
+		// the i3bar protocol is a header followed by an endless array
+		fmt.Println(`{"version":1}`)
+		fmt.Println("[")
+	}
+
+	ticker := time.NewTicker(cmd.interval)
+	defer ticker.Stop()
+	for {
+		fc, err := getForecast(g)
+		switch {
+		case err != nil && i3bar:
+			if err := writeI3barLine(forecast.I3barBlock{Name: "weather", FullText: "weather: " + err.Error(), ShortText: "weather: error", Color: "#dc322f"}); err != nil {
+				return err
+			}
+		case err != nil:
+			fmt.Fprintln(os.Stderr, err)
+		case i3bar:
+			if err := writeI3barLine(forecast.I3barRenderer{}.Block(fc, forecast.Options{IgnoreAlerts: ignoreAlerts})); err != nil {
+				return err
+			}
+		default:
+			if err := render(os.Stdout, fc, g); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeI3barLine writes the blocks as the next line of the i3bar protocol.
+func writeI3barLine(blocks ...forecast.I3barBlock) error {
+	b, err := json.Marshal(blocks)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Printf("%s,\n", b)
+	return err
+}
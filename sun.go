@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/genuinetools/weather/forecast"
+	"github.com/genuinetools/weather/geocode"
+	"github.com/sirupsen/logrus"
+)
+
+const sunHelp = `Show the times of sunrise, sunset, twilight and the moon.
+
+The times are calculated locally and shown in the location's time zone, so
+passing the location as coordinates and the time zone, e.g.
+-l 40.78,-73.95 -timezone America/New_York, needs no network.`
+
+func (cmd *sunCommand) Name() string      { return "sun" }
+func (cmd *sunCommand) Args() string      { return "[OPTIONS]" }
+func (cmd *sunCommand) ShortHelp() string { return "Show the sun and moon times for a day." }
+func (cmd *sunCommand) LongHelp() string  { return sunHelp }
+func (cmd *sunCommand) Hidden() bool      { return false }
+
+func (cmd *sunCommand) Register(fs *flag.FlagSet) {
+	fs.StringVar(&cmd.date, "date", "", "date to show in the form 2006-01-02 (default: today)")
+	fs.StringVar(&cmd.timezone, "timezone", "", "time zone of the location, e.g. Asia/Tokyo (default: the location's)")
+}
+
+type sunCommand struct {
+	date     string
+	timezone string
+}
+
+func (cmd *sunCommand) Run(ctx context.Context, args []string) error {
+	// "weather sun 3pm" runs this command rather than naming Sunday
+	if len(args) > 0 {
+		when := strings.Join(args, " ")
+		return fmt.Errorf("the sun command takes no arguments, for the weather on Sunday use \"weather sunday %s\" and for the sun times of a day -date", when)
+	}
+
+	g, ok := parseCoordinates(location)
+	if !ok {
+		var err error
+		g, err = getLocation()
+		if err != nil {
+			return err
+		}
+	}
+
+	loc := time.Local
+	if cmd.timezone != "" {
+		var err error
+		loc, err = time.LoadLocation(cmd.timezone)
+		if err != nil {
+			return fmt.Errorf("unknown time zone %q: %v", cmd.timezone, err)
+		}
+	} else {
+		zone, err := locationZone(g)
+		switch {
+		case err == nil:
+			loc = zone
+		case ok:
+			// the times of coordinates are still shown without the
+			// network, in ours
+			logrus.Warnf("getting the time zone of %s failed, showing local times: %v", g.City, err)
+		default:
+			return err
+		}
+	}
+
+	// a zone without a name from the forecast's offset is kept as the offset
+	_, offset := time.Now().In(loc).Zone()
+	fc := forecast.Forecast{
+		Latitude:  g.Latitude,
+		Longitude: g.Longitude,
+		Timezone:  loc.String(),
+		Offset:    float64(offset) / 60 / 60,
+	}
+
+	date := time.Now().In(fc.Location())
+	if cmd.date != "" {
+		var err error
+		date, err = time.ParseInLocation("2006-01-02", cmd.date, fc.Location())
+		if err != nil {
+			return fmt.Errorf("parsing date %q failed, expected the form 2006-01-02: %v", cmd.date, err)
+		}
+	}
+
+	place := g.City
+	if g.Region != "" {
+		place += ", " + g.Region
+	}
+
+	return forecast.PrintSun(os.Stdout, fc, place, date, forecast.Options{
+		Locale:    locale,
+		Clock:     clock,
+		LocalTime: localTime,
+	})
+}
+
+// parseCoordinates parses a location in the form "latitude,longitude" so
+// it does not need to be looked up.
+func parseCoordinates(s string) (geocode.Geocode, bool) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return geocode.Geocode{}, false
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return geocode.Geocode{}, false
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return geocode.Geocode{}, false
+	}
+
+	return geocode.Geocode{
+		City:      fmt.Sprintf("%.4f, %.4f", lat, lng),
+		Latitude:  lat,
+		Longitude: lng,
+	}, true
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/genuinetools/weather/config"
+	"github.com/genuinetools/weather/observations"
+)
+
+const trendsHelp = `Show the trends in the recorded weather of a saved location.
+
+The weather is recorded by "weather record". Each day shows the low, high and
+average temperature and the rainfall, and the latest day is compared to the
+rest of its month.`
+
+func (cmd *trendsCommand) Name() string      { return "trends" }
+func (cmd *trendsCommand) Args() string      { return "[OPTIONS] NAME" }
+func (cmd *trendsCommand) ShortHelp() string { return "Show trends in recorded weather." }
+func (cmd *trendsCommand) LongHelp() string  { return trendsHelp }
+func (cmd *trendsCommand) Hidden() bool      { return false }
+
+func (cmd *trendsCommand) Register(fs *flag.FlagSet) {
+	fs.IntVar(&cmd.last, "last", 30, "number of days to show, up to today")
+	fs.BoolVar(&cmd.csv, "csv", false, "write the days as CSV")
+	fs.StringVar(&cmd.dir, "dir", filepath.Join(config.StateDir(), "observations"), "directory the observations are kept in")
+}
+
+type trendsCommand struct {
+	last int
+	csv  bool
+	dir  string
+}
+
+func (cmd *trendsCommand) Run(ctx context.Context, args []string) error {
+	if cmd.last < 1 {
+		return fmt.Errorf("last must be at least 1 day")
+	}
+
+	store, err := observations.Open(cmd.dir)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		names, err := store.Locations()
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("pass the name of a location, none are recorded in %s", cmd.dir)
+		}
+		return fmt.Errorf("pass the name of a location, one of: %s", strings.Join(names, ", "))
+	}
+	name := args[0]
+
+	obs, err := store.Load(name)
+	if err != nil {
+		return err
+	}
+
+	conf, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	system := displayUnits(conf)
+
+	loc := observations.Location(obs)
+	now := time.Now().In(loc)
+	from := time.Date(now.Year(), now.Month(), now.Day()-cmd.last+1, 0, 0, 0, 0, loc)
+	days := observations.Days(obs, loc)
+
+	if cmd.csv {
+		return observations.WriteCSV(os.Stdout, observations.Since(days, from), system)
+	}
+	return observations.Print(os.Stdout, name, days, from, system)
+}
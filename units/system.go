@@ -0,0 +1,104 @@
+package units
+
+import (
+	"sort"
+)
+
+// Canonical is the name of the system the quantities are stored in.
+const Canonical = "si"
+
+// System is the unit of measure for each quantity.
+type System struct {
+	Temperature   string `json:"temperature,omitempty"`
+	Speed         string `json:"speed,omitempty"`
+	Distance      string `json:"distance,omitempty"`
+	Pressure      string `json:"pressure,omitempty"`
+	Precipitation string `json:"precipitation,omitempty"`
+}
+
+// Systems holds the systems of units by the names Dark Sky uses.
+var Systems = map[string]System{
+	"si":  {Celsius, MetersPerSecond, Kilometers, Hectopascals, MillimetersPerHour},
+	"ca":  {Celsius, KilometersPerHour, Kilometers, Hectopascals, MillimetersPerHour},
+	"uk":  {Celsius, MilesPerHour, Kilometers, Hectopascals, MillimetersPerHour}, // deprecated, use "uk2" in stead
+	"uk2": {Celsius, MilesPerHour, Miles, Hectopascals, MillimetersPerHour},
+	"us":  {Fahrenheit, MilesPerHour, Miles, Millibars, InchesPerHour},
+}
+
+// countrySystems are the systems used by countries that do not use "si",
+// by ISO 3166 country code.
+var countrySystems = map[string]string{
+	"US": "us",
+	"LR": "us",
+	"MM": "us",
+	"CA": "ca",
+	"GB": "uk2",
+}
+
+// ForCountry returns the name of the system used in the country, by ISO
+// 3166 country code, like Dark Sky's "auto" units.
+func ForCountry(code string) string {
+	if name, ok := countrySystems[code]; ok {
+		return name
+	}
+	return Canonical
+}
+
+// Override returns the system with the units set in o replacing its own.
+func (s System) Override(o System) System {
+	if o.Temperature != "" {
+		s.Temperature = o.Temperature
+	}
+	if o.Speed != "" {
+		s.Speed = o.Speed
+	}
+	if o.Distance != "" {
+		s.Distance = o.Distance
+	}
+	if o.Pressure != "" {
+		s.Pressure = o.Pressure
+	}
+	if o.Precipitation != "" {
+		s.Precipitation = o.Precipitation
+	}
+	return s
+}
+
+// Name returns the name of the system in Systems, or "custom" for a mix of
+// units.
+func (s System) Name() string {
+	names := make([]string, 0, len(Systems))
+	for name := range Systems {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if Systems[name] == s {
+			return name
+		}
+	}
+	return "custom"
+}
+
+// Validate returns an error if any of the units set are unknown.
+func (s System) Validate() error {
+	for _, c := range []struct {
+		quantity, unit string
+		names          []string
+	}{
+		{"temperature", s.Temperature, []string{Celsius, Fahrenheit}},
+		{"speed", s.Speed, names(speedFactors)},
+		{"distance", s.Distance, names(distanceFactors)},
+		{"pressure", s.Pressure, names(pressureFactors)},
+		{"precipitation", s.Precipitation, names(precipFactors)},
+	} {
+		if c.unit == "" {
+			continue
+		}
+		if err := check(c.quantity, c.unit, c.names); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+// Package units converts weather quantities between units of measure.
+//
+// Each quantity is stored in a canonical unit, the same as Dark Sky's "si"
+// units, and converted to and from the others by name, e.g.
+//
+//	units.SpeedIn(10, units.MilesPerHour).In(units.KilometersPerHour)
+package units
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Names of the units of measure.
+const (
+	Celsius    = "C"
+	Fahrenheit = "F"
+
+	MetersPerSecond   = "m/s"
+	KilometersPerHour = "km/h"
+	MilesPerHour      = "mph"
+	Knots             = "kn"
+
+	Kilometers = "km"
+	Miles      = "mi"
+
+	Hectopascals         = "hPa"
+	Millibars            = "mbar"
+	InchesOfMercury      = "inHg"
+	MillimetersOfMercury = "mmHg"
+
+	MillimetersPerHour = "mm/h"
+	InchesPerHour      = "in/h"
+)
+
+// The factors convert from the canonical unit of each quantity.
+var (
+	speedFactors = map[string]float64{
+		MetersPerSecond:   1,
+		KilometersPerHour: 3.6,
+		MilesPerHour:      3600 / 1609.344,
+		Knots:             3600 / 1852.0,
+	}
+	distanceFactors = map[string]float64{
+		Kilometers: 1,
+		Miles:      1 / 1.609344,
+	}
+	pressureFactors = map[string]float64{
+		Hectopascals:         1,
+		Millibars:            1,
+		InchesOfMercury:      1 / 33.8638866667,
+		MillimetersOfMercury: 1 / 1.33322387415,
+	}
+	precipFactors = map[string]float64{
+		MillimetersPerHour: 1,
+		InchesPerHour:      1 / 25.4,
+	}
+)
+
+// Temperature is a temperature in degrees Celsius.
+type Temperature float64
+
+// TemperatureIn returns the temperature of v in the unit.
+func TemperatureIn(v float64, unit string) Temperature {
+	if unit == Fahrenheit {
+		return Temperature((v - 32) * 5 / 9)
+	}
+	return Temperature(v)
+}
+
+// In returns the temperature in the unit.
+func (t Temperature) In(unit string) float64 {
+	if unit == Fahrenheit {
+		return float64(t)*9/5 + 32
+	}
+	return float64(t)
+}
+
+// Speed is a speed in meters per second.
+type Speed float64
+
+// SpeedIn returns the speed of v in the unit.
+func SpeedIn(v float64, unit string) Speed { return Speed(from(v, unit, speedFactors)) }
+
+// In returns the speed in the unit.
+func (s Speed) In(unit string) float64 { return to(float64(s), unit, speedFactors) }
+
+// Distance is a distance in kilometers.
+type Distance float64
+
+// DistanceIn returns the distance of v in the unit.
+func DistanceIn(v float64, unit string) Distance { return Distance(from(v, unit, distanceFactors)) }
+
+// In returns the distance in the unit.
+func (d Distance) In(unit string) float64 { return to(float64(d), unit, distanceFactors) }
+
+// Pressure is a pressure in hectopascals.
+type Pressure float64
+
+// PressureIn returns the pressure of v in the unit.
+func PressureIn(v float64, unit string) Pressure { return Pressure(from(v, unit, pressureFactors)) }
+
+// In returns the pressure in the unit.
+func (p Pressure) In(unit string) float64 { return to(float64(p), unit, pressureFactors) }
+
+// PrecipRate is a precipitation intensity in millimeters per hour.
+type PrecipRate float64
+
+// PrecipRateIn returns the precipitation intensity of v in the unit.
+func PrecipRateIn(v float64, unit string) PrecipRate { return PrecipRate(from(v, unit, precipFactors)) }
+
+// In returns the precipitation intensity in the unit.
+func (p PrecipRate) In(unit string) float64 { return to(float64(p), unit, precipFactors) }
+
+// from converts v from the unit to the canonical unit. Unknown units are
+// taken to be the canonical unit.
+func from(v float64, unit string, factors map[string]float64) float64 {
+	if f, ok := factors[unit]; ok {
+		return v / f
+	}
+	return v
+}
+
+// to converts v from the canonical unit to the unit.
+func to(v float64, unit string, factors map[string]float64) float64 {
+	if f, ok := factors[unit]; ok {
+		return v * f
+	}
+	return v
+}
+
+// Round rounds v to two decimal places, so converted values print like the
+// ones from the API.
+func Round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// names returns the sorted names of the units in factors.
+func names(factors map[string]float64) []string {
+	n := make([]string, 0, len(factors))
+	for name := range factors {
+		n = append(n, name)
+	}
+	sort.Strings(n)
+	return n
+}
+
+// check returns an error if the unit of the quantity is not in names.
+func check(quantity, unit string, names []string) error {
+	for _, name := range names {
+		if unit == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown %s unit %q, expected one of: %s", quantity, unit, strings.Join(names, ", "))
+}
@@ -0,0 +1,136 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	var (
+		temperature = func(v float64, from, to string) float64 { return TemperatureIn(v, from).In(to) }
+		speed       = func(v float64, from, to string) float64 { return SpeedIn(v, from).In(to) }
+		distance    = func(v float64, from, to string) float64 { return DistanceIn(v, from).In(to) }
+		pressure    = func(v float64, from, to string) float64 { return PressureIn(v, from).In(to) }
+		precip      = func(v float64, from, to string) float64 { return PrecipRateIn(v, from).In(to) }
+	)
+
+	testCases := []struct {
+		convert  func(v float64, from, to string) float64
+		v        float64
+		from, to string
+		expected float64
+	}{
+		{temperature, 0, Celsius, Fahrenheit, 32},
+		{temperature, 100, Celsius, Fahrenheit, 212},
+		{temperature, -40, Fahrenheit, Celsius, -40},
+		{temperature, 98.6, Fahrenheit, Celsius, 37},
+		{temperature, 21.5, Celsius, Celsius, 21.5},
+		{temperature, 70, Fahrenheit, Fahrenheit, 70},
+
+		{speed, 1, MetersPerSecond, MilesPerHour, 2.23694},
+		{speed, 1, MetersPerSecond, KilometersPerHour, 3.6},
+		{speed, 1, MetersPerSecond, Knots, 1.94384},
+		{speed, 60, MilesPerHour, KilometersPerHour, 96.5606},
+		{speed, 10, Knots, MilesPerHour, 11.5078},
+		// unknown units are taken to be the canonical unit
+		{speed, 7, "furlongs/fortnight", MetersPerSecond, 7},
+
+		{distance, 1, Miles, Kilometers, 1.609344},
+		{distance, 10, Kilometers, Miles, 6.21371},
+
+		{pressure, 1013.25, Hectopascals, InchesOfMercury, 29.9213},
+		{pressure, 1013.25, Hectopascals, MillimetersOfMercury, 760},
+		{pressure, 1013.25, Millibars, Hectopascals, 1013.25},
+		{pressure, 29.92, InchesOfMercury, Hectopascals, 1013.21},
+
+		{precip, 25.4, MillimetersPerHour, InchesPerHour, 1},
+		{precip, 0.1, InchesPerHour, MillimetersPerHour, 2.54},
+	}
+
+	for _, tc := range testCases {
+		got := tc.convert(tc.v, tc.from, tc.to)
+		if math.Abs(got-tc.expected) > 0.00001*math.Max(1, math.Abs(tc.expected)) {
+			t.Errorf("%g %s in %s: expected %g, got %g", tc.v, tc.from, tc.to, tc.expected, got)
+		}
+	}
+}
+
+func TestRound(t *testing.T) {
+	for v, expected := range map[float64]float64{
+		29.921252: 29.92,
+		2.236936:  2.24,
+		-3.335:    -3.34,
+		1013.25:   1013.25,
+	} {
+		if got := Round(v); got != expected {
+			t.Errorf("Round(%g): expected %g, got %g", v, expected, got)
+		}
+	}
+}
+
+func TestForCountry(t *testing.T) {
+	for code, expected := range map[string]string{
+		"US": "us",
+		"GB": "uk2",
+		"CA": "ca",
+		"LR": "us",
+		"DE": "si",
+		"":   "si",
+	} {
+		if got := ForCountry(code); got != expected {
+			t.Errorf("ForCountry(%q): expected %q, got %q", code, expected, got)
+		}
+	}
+}
+
+func TestSystemName(t *testing.T) {
+	testCases := []struct {
+		system   System
+		expected string
+	}{
+		{Systems["us"], "us"},
+		{Systems["si"], "si"},
+		{Systems["si"].Override(System{Speed: KilometersPerHour}), "ca"},
+		{Systems["us"].Override(System{Pressure: InchesOfMercury}), "custom"},
+		{Systems["uk2"].Override(System{}), "uk2"},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.system.Name(); got != tc.expected {
+			t.Errorf("%+v: expected %q, got %q", tc.system, tc.expected, got)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		system   System
+		expected string
+	}{
+		{system: Systems["us"]},
+		{system: System{}},
+		{system: System{Pressure: InchesOfMercury}},
+		{
+			system:   System{Temperature: "K"},
+			expected: `unknown temperature unit "K", expected one of: C, F`,
+		},
+		{
+			system:   System{Speed: MilesPerHour, Pressure: "atm"},
+			expected: `unknown pressure unit "atm", expected one of: hPa, inHg, mbar, mmHg`,
+		},
+		{
+			system:   System{Precipitation: "mm"},
+			expected: `unknown precipitation unit "mm", expected one of: in/h, mm/h`,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := tc.system.Validate()
+		switch {
+		case tc.expected == "" && err != nil:
+			t.Errorf("%+v: expected no error, got %v", tc.system, err)
+		case tc.expected != "" && (err == nil || err.Error() != tc.expected):
+			t.Errorf("%+v: expected the error %q, got %v", tc.system, tc.expected, err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/genuinetools/weather/forecast"
+	"github.com/mitchellh/colorstring"
+)
+
+const (
+	// minWatchInterval keeps watch from using up the API quota.
+	minWatchInterval = time.Minute
+
+	// ANSI escape sequences used to redraw the screen in place.
+	clearScreen = "\033[H\033[2J"
+	hideCursor  = "\033[?25l"
+	showCursor  = "\033[?25h"
+)
+
+const watchHelp = `Keep refreshing the current weather and highlight what changed.`
+
+func (cmd *watchCommand) Name() string      { return "watch" }
+func (cmd *watchCommand) Args() string      { return "[OPTIONS]" }
+func (cmd *watchCommand) ShortHelp() string { return watchHelp }
+func (cmd *watchCommand) LongHelp() string  { return watchHelp }
+func (cmd *watchCommand) Hidden() bool      { return false }
+
+func (cmd *watchCommand) Register(fs *flag.FlagSet) {
+	fs.DurationVar(&cmd.interval, "interval", 10*time.Minute, "how often to refresh the forecast")
+	fs.Float64Var(&cmd.tempSwing, "temp-swing", 3, "temperature change to highlight between refreshes")
+	fs.Float64Var(&cmd.rainThreshold, "rain-threshold", 0.5, "precipitation probability (0-1) to highlight when crossed")
+}
+
+type watchCommand struct {
+	interval      time.Duration
+	tempSwing     float64
+	rainThreshold float64
+}
+
+func (cmd *watchCommand) Run(ctx context.Context, args []string) error {
+	if cmd.interval < minWatchInterval {
+		return fmt.Errorf("interval must be at least %s", minWatchInterval)
+	}
+
+	// On ^C, or SIGTERM cancel the context so we can restore the terminal.
+	ctx, cancel := withSignals(ctx)
+	defer cancel()
+
+	g, err := getLocation()
+	if err != nil {
+		return err
+	}
+	geo = g
+
+	fmt.Print(hideCursor)
+	defer fmt.Print(showCursor)
+
+	ticker := time.NewTicker(cmd.interval)
+	defer ticker.Stop()
+
+	var (
+		previous forecast.Forecast
+		changes  []string
+		fetched  bool
+	)
+	for {
+		fc, err := getForecast(geo)
+		if err == nil {
+			if fetched {
+				changes = forecast.Changes(previous, fc, forecast.ChangeThresholds{
+					TemperatureSwing:  cmd.tempSwing,
+					PrecipProbability: cmd.rainThreshold,
+				})
+			}
+			previous, fetched = fc, true
+		}
+
+		fmt.Print(clearScreen)
+		if fetched {
+			if err := render(os.Stdout, previous, geo); err != nil {
+				return err
+			}
+		}
+		for _, change := range changes {
+			fmt.Println(colorstring.Color("[yellow][bold]* " + change))
+		}
+		if err != nil {
+			fmt.Println(colorstring.Color("[red]refreshing the forecast failed: " + err.Error()))
+		}
+		fmt.Printf("\nUpdated %s, next refresh in %s. Press Ctrl+C to exit.\n", time.Now().Format("3:04pm"), cmd.interval)
+
+		select {
+		case <-ctx.Done():
+			fmt.Println()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
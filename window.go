@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/genuinetools/weather/forecast"
+)
+
+const windowHelp = `Summarise the forecast for a recurring window of time saved in the config.
+
+The windows are saved by name in the config file, e.g.
+"commute": "weekdays 08:00-09:00 and 17:30-18:30", and each occurrence is
+summarised with the worst chance of precipitation, the lowest and highest
+temperatures, the strongest gusts and the alerts in effect.`
+
+func (cmd *windowCommand) Name() string      { return "window" }
+func (cmd *windowCommand) Args() string      { return "[OPTIONS] NAME" }
+func (cmd *windowCommand) ShortHelp() string { return "Summarise the forecast for a window." }
+func (cmd *windowCommand) LongHelp() string  { return windowHelp }
+func (cmd *windowCommand) Hidden() bool      { return false }
+
+func (cmd *windowCommand) Register(fs *flag.FlagSet) {
+	fs.IntVar(&cmd.next, "next", 1, "no. of occurrences of the window to summarise")
+	fs.IntVar(&cmd.next, "n", 1, "no. of occurrences of the window to summarise (shorthand)")
+}
+
+type windowCommand struct {
+	next int
+}
+
+func (cmd *windowCommand) Run(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("pass the name of a window saved in the config")
+	}
+	if cmd.next < 1 {
+		return fmt.Errorf("the no. of occurrences must be at least 1")
+	}
+	name := args[0]
+
+	conf, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	s, ok := conf.Windows[name]
+	if !ok {
+		names := make([]string, 0, len(conf.Windows))
+		for n := range conf.Windows {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return fmt.Errorf("there are no windows saved in %s", configPath)
+		}
+		return fmt.Errorf("unknown window %q, expected one of: %s", name, strings.Join(names, ", "))
+	}
+	schedule, err := forecast.ParseSchedule(s)
+	if err != nil {
+		return err
+	}
+
+	g, err := getLocation()
+	if err != nil {
+		return err
+	}
+	fc, err := getForecast(g, "minutely")
+	if err != nil {
+		return err
+	}
+
+	var summaries []forecast.WindowSummary
+	for _, o := range schedule.Occurrences(time.Unix(fc.Currently.Time, 0), fc.Horizon(), fc.Location(), cmd.next) {
+		summary, ok := forecast.SummarizeWindow(fc, o[0], o[1])
+		if !ok {
+			break
+		}
+		summaries = append(summaries, summary)
+	}
+	if len(summaries) == 0 {
+		return fmt.Errorf("the forecast does not cover the next %s window", name)
+	}
+
+	if format == "json" {
+		return json.NewEncoder(os.Stdout).Encode(map[string]interface{}{
+			"window":      name,
+			"schedule":    s,
+			"occurrences": summaries,
+		})
+	}
+	return forecast.PrintWindowSummaries(os.Stdout, fc, name, summaries, forecast.Options{
+		IgnoreAlerts: ignoreAlerts,
+		Locale:       locale,
+		Clock:        clock,
+		LocalTime:    localTime,
+	})
+}